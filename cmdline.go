@@ -34,25 +34,105 @@
 //	if cmd == "" {
 //	    cmdline.SyntaxError("Fehlendes Kommando!")
 //	}
+//
+// Die Callback-Funktion wird für jede Option und jedes Argument aufgerufen.
+// Bei Bedarf (z.B. für --help, zusammengefasste kurze Optionen oder unbekannte
+// Optionen) wird sie zusätzlich einmal im Scan-Modus aufgerufen, in dem alle
+// Is...-Methoden false liefern. Code außerhalb der case-Zweige (z.B. Zähler
+// oder ein default-Zweig) wird daher unterschiedlich oft ausgeführt.
 package cmdline
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
 	// der Name des Programms für die Ausgabe von Warn(), RuntimeError() und SyntaxError()
 	Program string
+	// der vollständige Pfad des Programms, wie er beim Parsen übergeben wurde
+	ProgramPath string
 	// der Hilfe-Text, der von PrintHelp() ausgegeben wird
 	Help string
+	// markiert in FormatHelp() eingerückte Zeilen (wird durch ein Space ersetzt,
+	// leer: keine Markierung)
+	FormatHelpPrefix = "|"
+	// FormatHelp() entfernt nur die gemeinsame Einrückung, damit z.B. eingerückte
+	// Beispiele erhalten bleiben
+	FormatHelpPreserveIndent bool
+	// wird von PrintHelp() auf jede Zeile des formatierten Hilfe-Textes angewendet
+	// (z.B. um Optionen hervorzuheben)
+	FormatHelpFunc func(line string) string = DontFormat
 	// diese Funktion wird bei einem Fehler aufgerufen
 	ErrorFunc func(format string, args ...any) = SyntaxError
 	// die Funktion, die für die Option --help verwendet werden soll
 	HelpFunc func(help string) = PrintHelp
+	// die kurze Option für --help (leer: nur --help)
+	HelpShortFlag = "h"
+	// beendet die Auswertung der Optionen, alle weiteren Argumente werden als
+	// normale Argumente ausgewertet (leer: "--")
+	OptionTerminator = "--"
+	// es gibt keine Ende-Markierung der Optionen (siehe [OptionTerminator])
+	DisableOptionTerminator bool
+	// die Version, die von PrintVersion() ausgegeben wird (leer: keine Option --version)
+	Version string
+	// die Funktion, die für die Option --version verwendet werden soll
+	VersionFunc func(version string) = PrintVersion
+	// das Trennzeichen zwischen Programm-Name und Meldung bei ProgramMessage()
+	MessageSeparator = ": "
+	// die Ausgabe für Info(), PrintHelp() und PrintVersion()
+	Stdout io.Writer = os.Stdout
+	// die Ausgabe für Warn(), RuntimeError() und SyntaxError()
+	Stderr io.Writer = os.Stderr
+	// erlaubt eindeutige Abkürzungen langer Optionen (z.B. --verb für --verbose)
+	AbbreviatedOptions bool
+	// ignoriert Groß-/Kleinschreibung bei Options-Namen (z.B. --FILE für --file)
+	CaseInsensitiveOptions bool
+	// behandelt negative Zahlen (z.B. -5) als Argumente statt als Optionen
+	AllowNegativeNumberArgs bool
+	// ersetzt Argumente der Form @file durch die Argumente aus der Datei file
+	ExpandResponseFiles bool
+	// parst bei Fehlern weiter und liefert am Ende alle Fehler zusammen
+	// (sinnvoll mit ErrorFunc = ReturnError)
+	CollectAllErrors bool
+	// Optionen mit einem "-" werden immer als ganzer Name ausgewertet (z.B. -verbose)
+	// und nie in zusammengefasste kurze Optionen zerlegt
+	SingleDashLongOptions bool
+	// nach dem ersten Argument werden alle weiteren Argumente nicht mehr als
+	// Optionen ausgewertet (wie nach "--"), z.B. bei "cmd --verbose"
+	PosixlyCorrect bool
+	// unbekannte Optionen werden unverändert als Argumente ausgewertet (z.B. "--unknown")
+	// statt einen Fehler zu melden
+	PassThroughUnknownOptions bool
+	// --help wird nicht automatisch ausgewertet, sondern wie jede andere Option
+	// an die Callback-Funktion übergeben
+	DisableAutoHelp bool
+	// wird für jede Option bzw. jedes Argument vor dem Aufruf der Callback-Funktion
+	// aufgerufen, z.B. zum Debuggen (nicht für Options-Argumente und "--")
+	OnToken func(raw string, isOption bool)
+	// meldet einen Fehler, falls eine Option mehrfach angegeben wird
+	// (außer Zähler-, Listen- und Map-Optionen)
+	RejectDuplicateOptions bool
+	// die maximale Länge eines Options-Arguments in Zeichen (0: unbegrenzt)
+	MaxOptValueLen int
+	// "--" wird nicht verworfen, sondern zusätzlich als Argument ausgewertet
+	// (z.B. um die Argumente an ein anderes Programm weiterzugeben)
+	KeepDoubleDash bool
+	// bei --help wird statt HelpFunc aufzurufen ErrHelpRequested zurückgegeben
+	HelpAsError bool
+	// prüft vor dem Parsen, ob ein kurzer oder langer Options-Name für mehrere
+	// Optionen verwendet wird, und löst ggf. eine panic aus (Programmierfehler)
+	CheckOptionNames bool
 )
 
 //--------------------------------------------------------------------------------
@@ -65,18 +145,36 @@ func ReturnError(format string, args ...any) {
 }
 
 // Parst einen mehrzeiligen Help-String und trimmt führende Spaces.
-// Falls eine Zeile mit einem "|"-Zeichen beginnt, wird dieses durch ein Space ersetzt.
+// Falls eine Zeile mit [FormatHelpPrefix] (Default: "|") beginnt, wird dieses
+// durch ein Space ersetzt. Jedes weitere "|" rückt die Zeile um zwei Spaces
+// weiter ein ("| Text" und "|| Text" ergeben 2 bzw. 4 Spaces vor "Text").
 // Leerzeilen am Ende werden entfernt.
+//
+// Mit [FormatHelpPreserveIndent] wird bei den übrigen Zeilen (außer der ersten)
+// nur die gemeinsame Einrückung entfernt, weitere Einrückungen bleiben erhalten.
 func FormatHelp(help string) string {
 	lines := strings.Split(help, "\n")
 
+	indent := ""
+	if FormatHelpPreserveIndent {
+		indent = commonIndent(lines[1:])
+	}
+
 	for i, line := range lines {
-		line := strings.TrimSpace(line)
-		after, found := strings.CutPrefix(line, "|")
-		if found {
-			line = " " + after
+		trimmed := strings.TrimSpace(line)
+		after, found := strings.CutPrefix(trimmed, FormatHelpPrefix)
+		if found && FormatHelpPrefix != "" {
+			level := 1
+			for strings.HasPrefix(after, FormatHelpPrefix) {
+				after = after[len(FormatHelpPrefix):]
+				level++
+			}
+			lines[i] = strings.Repeat("  ", level-1) + " " + after
+		} else if FormatHelpPreserveIndent && i > 0 {
+			lines[i] = strings.TrimRight(strings.TrimPrefix(line, indent), " \t")
+		} else {
+			lines[i] = trimmed
 		}
-		lines[i] = line
 	}
 
 	n_lines := len(lines)
@@ -92,52 +190,203 @@ func FormatHelp(help string) string {
 	return strings.Join(lines, "\n")
 }
 
-// Parst [Help] mit [FormatHelp], gibt das Ergebnis auf Stdout aus und beendet mit os.Exit(0).
+// Formatiert help wie [FormatHelp] und bricht zu lange Zeilen an Wortgrenzen um.
+// Die Folgezeilen werden unter dem ersten Wort der Beschreibung eingerückt, also
+// nach mindestens zwei Spaces (z.B. "  -v, --verbose  Beschreibung") bzw. unter
+// dem ersten Wort der Zeile.
+// Ist width 0, wird die Breite aus der Umgebungsvariablen COLUMNS übernommen
+// (Default: 80).
+func FormatHelpWidth(help string, width int) string {
+	if width <= 0 {
+		width = terminalWidth()
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(FormatHelp(help), "\n") {
+		lines = append(lines, wrapHelpLine(line, width)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Liefert die Breite des Terminals aus der Umgebungsvariablen COLUMNS.
+func terminalWidth() int {
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return helpWidth
+}
+
+// Bricht eine Zeile des Hilfe-Textes um (siehe [FormatHelpWidth]).
+func wrapHelpLine(line string, width int) []string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return []string{line}
+	}
+
+	text := strings.TrimLeft(line, " ")
+	descCol := len(runes) - len([]rune(text))
+	if idx := strings.Index(text, "  "); idx > 0 {
+		desc := strings.TrimLeft(text[idx:], " ")
+		descCol = len(runes) - len([]rune(desc))
+	}
+
+	descWidth := width - descCol
+	if descWidth < 20 {
+		descWidth = 20
+	}
+
+	desc := wrapWords(string(runes[descCol:]), descWidth)
+	if len(desc) == 0 {
+		return []string{line}
+	}
+
+	indent := strings.Repeat(" ", descCol)
+	lines := []string{string(runes[:descCol]) + desc[0]}
+	for _, descLine := range desc[1:] {
+		lines = append(lines, indent+descLine)
+	}
+	return lines
+}
+
+// Liefert die Einrückung (Spaces und Tabs), mit der alle nicht-leeren Zeilen beginnen.
+func commonIndent(lines []string) string {
+	indent, first := "", true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			indent, first = lineIndent, false
+			continue
+		}
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+	return indent
+}
+
+// Parst [Help] mit [FormatHelp], gibt das Ergebnis auf [Stdout] aus und beendet mit os.Exit(0).
 func PrintHelp(help string) {
-	fmt.Println(FormatHelp(help))
+	FprintHelp(Stdout, help)
+	os.Exit(0)
+}
+
+// Parst help mit [FormatHelp] und gibt das Ergebnis auf w aus (ohne Programmende).
+// Jede Zeile wird dabei mit [FormatHelpFunc] formatiert.
+func FprintHelp(w io.Writer, help string) {
+	lines := strings.Split(FormatHelp(help), "\n")
+	if FormatHelpFunc != nil {
+		for i, line := range lines {
+			lines[i] = FormatHelpFunc(line)
+		}
+	}
+	fmt.Fprintln(w, strings.Join(lines, "\n"))
+}
+
+// Kann als [FormatHelpFunc] verwendet werden und liefert line unverändert.
+func DontFormat(line string) string {
+	return line
+}
+
+// Liefert f nur, falls w ein Terminal ist, ansonsten [DontFormat].
+// Damit werden z.B. ANSI-Farben nicht in eine Datei oder Pipe ausgegeben.
+// w ist die Ausgabe, für die formatiert wird (z.B. [Stdout] für die Hilfe
+// oder [Stderr] für Warnungen):
+//
+//	cmdline.FormatHelpFunc = cmdline.AutoFormat(cmdline.Stdout, highlight)
+func AutoFormat(w io.Writer, f func(string) string) func(string) string {
+	if !isTerminal(w) {
+		return DontFormat
+	}
+	return f
+}
+
+// Prüft, ob w ein Terminal (Character-Device) ist.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Gibt [Program] und die Version auf [Stdout] aus und beendet mit os.Exit(0).
+func PrintVersion(version string) {
+	printVersion(Program, version)
+}
+
+// Gibt program und die Version auf [Stdout] aus und beendet mit os.Exit(0).
+func printVersion(program, version string) {
+	fmt.Fprintf(Stdout, "%s %s\n", program, version)
 	os.Exit(0)
 }
 
-// Gibt eine Fehlermeldung mit "Verwenden Sie --help ..." auf Stderr aus und
+// Gibt eine Fehlermeldung mit "Verwenden Sie --help ..." auf [Stderr] aus und
 // beendet mit os.Exit(1)
 func SyntaxError(format string, args ...any) {
+	syntaxError(Program, &Msgs, format, args...)
+}
+
+// Gibt eine Fehlermeldung mit msgs.UseHelp für program auf [Stderr] aus und
+// beendet mit os.Exit(1).
+func syntaxError(program string, msgs *Messages, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	Warn("%s\n\nVerwenden Sie --help für weitere Hilfe!", msg)
+	programMessage(Stderr, program, msgs.UseHelp, msg)
 	os.Exit(1)
 }
 
-// Gibt eine Fehlermeldung auf [os.Stderr] aus und beendet mit os.Exit(1).
+// Gibt eine Fehlermeldung auf [Stderr] aus und beendet mit os.Exit(1).
 // Siehe [ProgramMessage].
 func RuntimeError(format string, args ...any) {
 	Warn(format, args...)
 	os.Exit(1)
 }
 
-// Gibt eine Fehlermeldung im Format "Program: Fehler" auf [os.Stderr] aus.
+// Liefert eine Fehlermeldung im Format "Program: Fehler" als error, statt sie wie
+// [RuntimeError] auszugeben und das Programm zu beenden.
+func NewRuntimeError(format string, args ...any) error {
+	return errors.New(Program + MessageSeparator + fmt.Sprintf(format, args...))
+}
+
+// Gibt eine Fehlermeldung im Format "Program: Fehler" auf [Stderr] aus.
 // Siehe [ProgramMessage].
 func Warn(format string, args ...any) {
-	ProgramMessage(os.Stderr, format, args...)
+	ProgramMessage(Stderr, format, args...)
 }
 
-// Git eine Meldung im Format "Program: Meldung" auf [os.Stdout] aus.
+// Git eine Meldung im Format "Program: Meldung" auf [Stdout] aus.
 // Siehe [ProgramMessage].
 func Info(format string, args ...any) {
-	ProgramMessage(os.Stdout, format, args...)
+	ProgramMessage(Stdout, format, args...)
 }
 
 // Gibt eine Meldung im Format "Program: Message" auf `fd` aus.
+// Das Trennzeichen nach dem Programm-Namen bestimmt [MessageSeparator].
 // Die Meldung kann auch mehrzeilig sein. In diesem Fall wird nur in
 // der ersten Zeile der Programm-Name ausgegeben. Alle weiteren Zeilen
-// werden entsprechend eingerückt.
-func ProgramMessage(fd *os.File, format string, args ...any) {
+// werden entsprechend eingerückt (Leerzeilen ohne Einrückung).
+// Jede Zeile wird mit genau einem Zeilenumbruch abgeschlossen.
+func ProgramMessage(fd io.Writer, format string, args ...any) {
+	programMessage(fd, Program, format, args...)
+}
+
+// Gibt eine Meldung wie [ProgramMessage] aus, aber mit dem Programm-Namen program.
+func programMessage(fd io.Writer, program, format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
 	lines := strings.Split(msg, "\n")
-	fmt.Fprintf(fd, "%s: %s\n", Program, lines[0])
+	fmt.Fprintf(fd, "%s%s%s\n", program, MessageSeparator, lines[0])
 	if len(lines) > 1 {
-		indentLen := len([]rune(Program)) + 2
+		indentLen := len([]rune(program)) + len([]rune(MessageSeparator))
 		indent := strings.Repeat(" ", indentLen)
 		for _, line := range lines[1:] {
-			fmt.Fprintf(fd, "%s%s\n", indent, line)
+			if line == "" {
+				fmt.Fprintln(fd)
+			} else {
+				fmt.Fprintf(fd, "%s%s\n", indent, line)
+			}
 		}
 	}
 }
@@ -148,192 +397,2038 @@ func ProgramMessage(fd *os.File, format string, args ...any) {
 
 // Wird von [Parse] bzw. [ParseArgs] an die Funktion übergeben.
 type Parser struct {
-	rest     []string
-	argIdx   int
-	onlyArgs bool
-	opt      string
-	strVal   string
-	intVal   int
-	grabbed  bool
-	err      error
+	rest          []string
+	argIdx        int
+	args          []string
+	onlyArgs      bool
+	terminated    bool
+	opt           string
+	rawOpt        string
+	plusOpt       bool
+	strVal        string
+	secondVal     string
+	hasVal        bool
+	intVal        int
+	uintVal       uint64
+	floatVal      float64
+	sizeVal       int64
+	countVal      int
+	boolVal       bool
+	csvVal        []string
+	duration      time.Duration
+	counts        map[string]int
+	slices        map[string][]string
+	intSlices     map[string][]int
+	maps          map[string]map[string]string
+	grabbed       bool
+	strOk         bool
+	boolOk        bool
+	intOk         bool
+	stopped       bool
+	helpRequested bool
+	changed       map[string]bool
+	provided      []ProvidedOption
+	err           error
+	errs          []error
+	config        *Config
+	fn            func(*Parser)
+	outerFns      []func(*Parser)
+	scanning      bool
+	known         []*optInfo
+	plusOpts      []string
+	argNames      map[int]string
+	commands      []string
+}
+
+// Beschreibt eine Option, auf die in der Callback-Funktion geprüft wird.
+type optInfo struct {
+	long       string
+	short      string
+	hasValue   bool
+	repeatable bool
+	desc       string
+	aliases    []string
+}
+
+// Liefert den langen Namen und die weiteren langen Namen (siehe [Parser.IsStrOptAlias]).
+func (info *optInfo) longNames() []string {
+	return append([]string{info.long}, info.aliases...)
+}
+
+// Enthält die Einstellungen für einen Parser.
+// Im Gegensatz zu den Paket-Variablen ([Program], [Help], [ErrorFunc], [HelpFunc])
+// können damit mehrere unabhängige Parser verwendet werden.
+// Nicht gesetzte Funktionen werden durch [SyntaxError], [PrintHelp] bzw. [PrintVersion] ersetzt,
+// wobei Fehlermeldung und Version den Program-Namen der Config verwenden.
+// Die Config selbst wird beim Parsen nicht verändert (jeder Parser verwendet eine
+// Kopie), sie kann also auch von mehreren Parsern gleichzeitig verwendet werden.
+type Config struct {
+	// der Name des Programms (leer: aus dem ersten Argument, siehe [Parser.Program])
+	Program string
+	// der vollständige Pfad des Programms (wird beim Parsen aus dem ersten Argument
+	// übernommen, siehe [Parser.ProgramPath])
+	ProgramPath string
+	// der Hilfe-Text für die Option --help
+	Help string
+	// diese Funktion wird bei einem Fehler aufgerufen
+	ErrorFunc func(format string, args ...any)
+	// die Funktion, die für die Option --help verwendet werden soll
+	HelpFunc func(help string)
+	// die kurze Option für --help (leer: nur --help)
+	HelpShortFlag string
+	// beendet die Auswertung der Optionen (leer: "--")
+	OptionTerminator string
+	// es gibt keine Ende-Markierung der Optionen
+	DisableOptionTerminator bool
+	// die Version für die Option --version (leer: keine Option --version)
+	Version string
+	// die Funktion, die für die Option --version verwendet werden soll
+	VersionFunc func(version string)
+	// die Meldungen des Parsers (nil: DefaultMessages)
+	Messages *Messages
+	// erlaubt eindeutige Abkürzungen langer Optionen (z.B. --verb für --verbose)
+	AbbreviatedOptions bool
+	// ignoriert Groß-/Kleinschreibung bei Options-Namen (z.B. --FILE für --file)
+	CaseInsensitiveOptions bool
+	// behandelt negative Zahlen (z.B. -5) als Argumente statt als Optionen
+	AllowNegativeNumberArgs bool
+	// ersetzt Argumente der Form @file durch die Argumente aus der Datei file
+	ExpandResponseFiles bool
+	// parst bei Fehlern weiter und liefert am Ende alle Fehler zusammen
+	// (sinnvoll mit ErrorFunc = ReturnError)
+	CollectAllErrors bool
+	// Optionen mit einem "-" werden immer als ganzer Name ausgewertet (z.B. -verbose)
+	// und nie in zusammengefasste kurze Optionen zerlegt
+	SingleDashLongOptions bool
+	// nach dem ersten Argument werden alle weiteren Argumente nicht mehr als
+	// Optionen ausgewertet (wie nach "--"), z.B. bei "cmd --verbose"
+	PosixlyCorrect bool
+	// unbekannte Optionen werden unverändert als Argumente ausgewertet (z.B. "--unknown")
+	// statt einen Fehler zu melden
+	PassThroughUnknownOptions bool
+	// --help wird nicht automatisch ausgewertet, sondern wie jede andere Option
+	// an die Callback-Funktion übergeben
+	DisableAutoHelp bool
+	// wird für jede Option bzw. jedes Argument vor dem Aufruf der Callback-Funktion
+	// aufgerufen, z.B. zum Debuggen (nicht für Options-Argumente und "--")
+	OnToken func(raw string, isOption bool)
+	// meldet einen Fehler, falls eine Option mehrfach angegeben wird
+	// (außer Zähler-, Listen- und Map-Optionen)
+	RejectDuplicateOptions bool
+	// die maximale Länge eines Options-Arguments in Zeichen (0: unbegrenzt)
+	MaxOptValueLen int
+	// "--" wird nicht verworfen, sondern zusätzlich als Argument ausgewertet
+	// (z.B. um die Argumente an ein anderes Programm weiterzugeben)
+	KeepDoubleDash bool
+	// bei --help wird statt HelpFunc aufzurufen ErrHelpRequested zurückgegeben
+	HelpAsError bool
+	// prüft vor dem Parsen, ob ein kurzer oder langer Options-Name für mehrere
+	// Optionen verwendet wird, und löst ggf. eine panic aus (Programmierfehler)
+	CheckOptionNames bool
+}
+
+// Liefert eine Config mit den Werten der Paket-Variablen.
+func globalConfig() *Config {
+	return &Config{
+		Program:       Program,
+		ProgramPath:   ProgramPath,
+		Help:          Help,
+		ErrorFunc:     ErrorFunc,
+		HelpFunc:      HelpFunc,
+		HelpShortFlag: HelpShortFlag,
+		Version:       Version,
+		VersionFunc:   VersionFunc,
+		Messages:      &Msgs,
+
+		OptionTerminator:        OptionTerminator,
+		DisableOptionTerminator: DisableOptionTerminator,
+
+		AbbreviatedOptions:        AbbreviatedOptions,
+		CaseInsensitiveOptions:    CaseInsensitiveOptions,
+		AllowNegativeNumberArgs:   AllowNegativeNumberArgs,
+		ExpandResponseFiles:       ExpandResponseFiles,
+		CollectAllErrors:          CollectAllErrors,
+		SingleDashLongOptions:     SingleDashLongOptions,
+		PosixlyCorrect:            PosixlyCorrect,
+		PassThroughUnknownOptions: PassThroughUnknownOptions,
+		DisableAutoHelp:           DisableAutoHelp,
+		OnToken:                   OnToken,
+		RejectDuplicateOptions:    RejectDuplicateOptions,
+		MaxOptValueLen:            MaxOptValueLen,
+		KeepDoubleDash:            KeepDoubleDash,
+		HelpAsError:               HelpAsError,
+		CheckOptionNames:          CheckOptionNames,
+	}
+}
+
+// Parst die übergebenen Argumente wie [ParseArgs], verwendet aber die Einstellungen der Config.
+func (config *Config) ParseArgs(args []string, fn func(*Parser)) error {
+	return config.NewParser(args).Parse(fn)
+}
+
+// Parst eine ganze Kommandozeile wie [ParseLine], verwendet aber die Einstellungen der Config.
+func (config *Config) ParseLine(line string, fn func(*Parser)) error {
+	args, quote := splitLine(line)
+	parser := config.NewParser(args)
+	if quote != 0 {
+		return parser.errorf(OtherError, parser.msgs().UnclosedQuote, string(quote))
+	}
+	return parser.Parse(fn)
+}
+
+// Erzeugt einen Parser wie [NewParser], verwendet aber die Einstellungen der Config.
+func (config *Config) NewParser(args []string) *Parser {
+	copied := *config
+	config = &copied
+
+	if len(args) > 0 {
+		if config.Program == "" {
+			config.Program = path.Base(args[0])
+		}
+		config.ProgramPath = args[0]
+		args = args[1:]
+	} else if len(os.Args) > 0 {
+		if config.Program == "" {
+			config.Program = path.Base(os.Args[0])
+		}
+		if config.ProgramPath == "" {
+			config.ProgramPath = os.Args[0]
+		}
+	}
+
+	if config.Messages == nil {
+		config.Messages = &DefaultMessages
+	}
+	if config.OptionTerminator == "" {
+		config.OptionTerminator = "--"
+	}
+	if config.ErrorFunc == nil {
+		config.ErrorFunc = config.syntaxError
+	}
+	if config.HelpFunc == nil {
+		config.HelpFunc = PrintHelp
+	}
+	if config.VersionFunc == nil {
+		config.VersionFunc = config.printVersion
+	}
+
+	return &Parser{rest: args, config: config}
+}
+
+// Wie [SyntaxError], aber mit dem Programm-Namen und den Meldungen der Config.
+func (config *Config) syntaxError(format string, args ...any) {
+	syntaxError(config.Program, config.Messages, format, args...)
+}
+
+// Wie [PrintVersion], aber mit dem Programm-Namen der Config.
+func (config *Config) printVersion(version string) {
+	printVersion(config.Program, version)
 }
 
 // Parst die Kommandozeilen-Argument ([os.Args]) mittels [ParseArgs].
+// Zu den Aufrufen der Callback-Funktion siehe [Parser.Parse].
 func Parse(fn func(*Parser)) error {
 	return ParseArgs(os.Args, fn)
 }
 
 // Parst die übergebenen Argumente.
 // Das erste Argument muß der Pfad des Executables sein (wird ggf. verwendet um [Program] zu setzen).
+// Ohne Argumente wird [Program] ggf. aus [os.Args] übernommen.
 // Mit Hilfe des übergebenen Parsers können dann die Argumente und Optionen ausgewertet werden.
+// Zu den Aufrufen der Callback-Funktion siehe [Parser.Parse].
 func ParseArgs(args []string, fn func(*Parser)) error {
-	if len(args) > 0 {
-		if Program == "" {
-			Program = path.Base(args[0])
+	return NewParser(args).Parse(fn)
+}
+
+// Zerlegt eine ganze Kommandozeile wie eine Shell in Argumente und parst diese
+// mittels [ParseArgs] (z.B. für eine Eingabezeile in einem REPL).
+// Einfache und doppelte Anführungszeichen sowie "\" werden wie in der Shell ausgewertet:
+//
+//	cmd --file="a b.txt" 'c d' e\ f
+//
+// Das erste Argument ist der Name des Kommandos.
+// Die Anführungszeichen werden auch nach "--" ausgewertet, "--" beendet nur
+// die Auswertung der Optionen (ein "--" in Anführungszeichen ist ebenfalls "--").
+func ParseLine(line string, fn func(*Parser)) error {
+	args, quote := splitLine(line)
+	parser := NewParser(args)
+	if quote != 0 {
+		return parser.errorf(OtherError, parser.msgs().UnclosedQuote, string(quote))
+	}
+	return parser.Parse(fn)
+}
+
+// Zerlegt line wie eine Shell in Argumente (siehe [ParseLine]).
+// Fehlt ein schließendes Anführungszeichen, wird dieses als quote geliefert.
+func splitLine(line string) (args []string, quote rune) {
+	args = []string{}
+	arg := strings.Builder{}
+	inArg, escaped := false, false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
 		}
-		args = args[1:]
 	}
 
-	parser := &Parser{rest: args}
+	if escaped {
+		arg.WriteRune('\\')
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, quote
+}
+
+// Erzeugt einen Parser für die übergebenen Argumente, der erst mit [Parser.Parse]
+// ausgewertet wird. Im Gegensatz zu [ParseArgs] kann der Parser danach noch
+// abgefragt werden (z.B. mit [Parser.Changed]).
+// Das erste Argument muß der Pfad des Executables sein (wird ggf. verwendet um [Program] zu setzen).
+// Zu den Aufrufen der Callback-Funktion siehe [Parser.Parse].
+func NewParser(args []string) *Parser {
+	parser := globalConfig().NewParser(args)
+	Program, ProgramPath = parser.config.Program, parser.config.ProgramPath
+	return parser
+}
+
+// Liefert den Namen des Programms (siehe [Config].Program).
+func (parser *Parser) Program() string {
+	return parser.config.Program
+}
+
+// Liefert den vollständigen Pfad des Programms, wie er beim Parsen übergeben wurde.
+func (parser *Parser) ProgramPath() string {
+	return parser.config.ProgramPath
+}
+
+// Parst die Argumente des Parsers.
+// Mit Hilfe des übergebenen Parsers können dann die Argumente und Optionen ausgewertet werden.
+//
+// fn wird für jede Option und jedes Argument aufgerufen. Werden die bekannten
+// Optionen benötigt (z.B. für --help, zusammengefasste kurze Optionen wie "-vx",
+// "+name", unbekannte Optionen oder [CheckOptionNames]), wird fn zusätzlich einmal
+// im Scan-Modus aufgerufen, in dem alle Is...-Methoden false liefern (nach
+// [Parser.ParseRest] ggf. erneut). Code außerhalb der case-Zweige (z.B. Zähler,
+// Protokollierung oder ein default-Zweig) sollte daher keine Seiteneffekte haben.
+// Fehler (z.B. mit [Parser.Errorf]) werden im Scan-Modus ignoriert.
+func (parser *Parser) Parse(fn func(*Parser)) error {
+	parser.fn = fn
+	if parser.config.CheckOptionNames {
+		parser.knownOpts()
+	}
 
 	for len(parser.rest) > 0 {
 		arg := parser.popNextArg()
 
+		if !parser.onlyArgs && parser.config.ExpandResponseFiles && isResponseFile(arg) {
+			args, err := parser.readResponseFile(arg, 1)
+			if err != nil {
+				return err
+			}
+			parser.rest = append(args, parser.rest...)
+			continue
+		}
+
+		if !parser.onlyArgs && !parser.config.SingleDashLongOptions {
+			if flags, ok := parser.splitShortOpts(arg); ok {
+				parser.rest = append(flags, parser.rest...)
+				arg = parser.popNextArg()
+			}
+		}
+
 		if parser.onlyArgs == true {
-			parser.opt = ""
+			parser.opt, parser.rawOpt, parser.plusOpt = "", "", false
 			parser.strVal = arg
-		} else if arg == "--help" {
-			HelpFunc(Help)
+			parser.hasVal = false
+		} else if parser.isHelpOpt(arg) {
+			parser.helpRequested = true
+			if parser.config.HelpAsError {
+				return ErrHelpRequested
+			}
+			help := parser.config.Help
+			if help == "" {
+				help = parser.msgs().HelpHeading + "\n" + parser.BuildHelp()
+			}
+			parser.config.HelpFunc(help)
+			return nil
+		} else if arg == "--version" && parser.config.Version != "" {
+			parser.config.VersionFunc(parser.config.Version)
 			return nil
+		} else if parser.isOptionTerminator(arg) {
+			// jedes weitere "--" wird danach als normales Argument ausgewertet
+			parser.onlyArgs, parser.terminated = true, true
+			if !parser.config.KeepDoubleDash {
+				continue
+			}
+			parser.opt, parser.rawOpt, parser.plusOpt = "", "", false
+			parser.strVal = arg
+			parser.hasVal = false
 		} else {
-			if arg == "--" {
-				if len(parser.rest) == 0 {
-					return parser.err
+			parser.opt, parser.strVal, parser.hasVal = parser.parseArg(arg)
+			parser.rawOpt = ""
+			if parser.opt != "" {
+				parser.rawOpt, _, _ = strings.Cut(arg, "=")
+			}
+			parser.plusOpt = parser.opt != "" && strings.HasPrefix(arg, "+")
+
+			if parser.config.AbbreviatedOptions {
+				if err := parser.expandAbbreviation(); err != nil {
+					if parser.config.CollectAllErrors {
+						continue
+					}
+					return err
 				}
-				parser.onlyArgs = true
-				arg = parser.popNextArg()
 			}
+		}
 
-			parser.opt, parser.strVal = parser.parseArg(arg)
+		if parser.config.OnToken != nil {
+			parser.config.OnToken(arg, parser.opt != "")
 		}
 
-		parser.grabbed = false
+		parser.grabbed, parser.strOk, parser.intOk, parser.boolOk = false, false, false, false
+		errCount := len(parser.errs)
 
-		fn(parser)
+		parser.fn(parser)
+
+		failed := len(parser.errs) > errCount
+
+		if !failed && !parser.grabbed && parser.opt != "" && parser.config.PassThroughUnknownOptions {
+			parser.opt, parser.rawOpt, parser.plusOpt = "", "", false
+			parser.strVal, parser.hasVal = arg, false
+			parser.fn(parser)
+			failed = len(parser.errs) > errCount
+		}
+
+		if !failed && parser.grabbed && parser.opt != "" {
+			value := parser.strVal
+			if parser.boolOk {
+				value = strconv.FormatBool(parser.boolVal)
+			}
+			parser.provided = append(parser.provided, ProvidedOption{parser.opt, value})
+		}
 
-		if parser.err != nil {
+		if failed && !parser.config.CollectAllErrors {
 			return parser.err
 		}
 
-		if !parser.grabbed {
+		if parser.stopped {
+			return parser.result()
+		}
+
+		if !failed && !parser.grabbed {
 			if parser.opt != "" {
-				return parser.Errorf("Unbekannte Option: --%s", parser.opt)
+				parser.unknownOptError()
 			} else {
-				return parser.Errorf("Zu viele Argumente!")
+				parser.errorf(TooManyArgs, "%s", parser.msgs().TooManyArgs)
+			}
+			if !parser.config.CollectAllErrors {
+				return parser.err
 			}
 		}
 	}
-	return nil
+	return parser.result()
 }
 
-func (parser *Parser) popNextArg() string {
-	if len(parser.rest) == 0 {
-		return ""
+// Prüft, ob arg die automatisch ausgewertete Option --help ist (siehe [DisableAutoHelp]).
+// Die kurze Option [HelpShortFlag] wird nur erkannt, falls sie nicht von der
+// Callback-Funktion selbst verwendet wird.
+func (parser *Parser) isHelpOpt(arg string) bool {
+	if parser.config.DisableAutoHelp {
+		return false
 	}
-	arg := parser.rest[0]
-	parser.rest = parser.rest[1:]
-	return arg
-}
-
-func (parser *Parser) parseArg(arg string) (opt, strVal string) {
-	if strings.HasPrefix(arg, "-") {
-		parts := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)
-		opt = parts[0]
-		if opt == "" {
-			opt = "???"
-		}
-		if len(parts) > 1 {
-			strVal = parts[1]
-		}
-	} else {
-		strVal = arg
+	if arg == "--help" {
+		return true
 	}
+	short := parser.config.HelpShortFlag
+	return short != "" && arg == "-"+short && parser.findOpt(short) == nil
+}
 
-	return opt, strVal
+// Prüft, ob arg die Ende-Markierung der Optionen ist (siehe [OptionTerminator]).
+func (parser *Parser) isOptionTerminator(arg string) bool {
+	return !parser.config.DisableOptionTerminator && arg == parser.config.OptionTerminator
 }
 
-// Gibt entweder eine [SyntaxError]-Meldung auf Stderr aus oder setzt die
-// Fehlermeldung, welche von [Parse] bzw. [ParseArgs] zurückgegeben werden soll.
-// Welche Aktion ausgeführt werden soll bestimmt [ErrorFunc].
-func (parser *Parser) Errorf(format string, args ...any) error {
-	ErrorFunc(format, args...)
-	parser.err = fmt.Errorf(format, args...)
+// Liefert den Fehler, der von [Parser.Parse] zurückgegeben wird.
+// Bei mehreren Fehlern (siehe [CollectAllErrors]) werden diese mit [errors.Join] zusammengefasst.
+func (parser *Parser) result() error {
+	if len(parser.errs) > 1 {
+		return errors.Join(parser.errs...)
+	}
 	return parser.err
 }
 
-//--------------------------------------------------------------------------------
-// Argumente/Optionen prüfen
-//--------------------------------------------------------------------------------
+// Liefert true, falls das Parsen durch --help beendet wurde.
+// Damit kann z.B. mit einer leeren [HelpFunc] selbst entschieden werden,
+// wie die Hilfe ausgegeben wird.
+//
+// Für --help bzw. -h werden keine Optionen oder Argumente an die Callback-Funktion
+// übergeben. Nur für die automatisch erzeugte Hilfe (leerer [Help]) bzw. um zu
+// prüfen, ob -h selbst als Option verwendet wird, wird sie einmal im Scan-Modus
+// aufgerufen, in dem alle Is...-Methoden false liefern (siehe [Parser.Parse]).
+func (parser *Parser) HelpRequested() bool {
+	return parser.helpRequested
+}
 
-// Prüft auf Optionen ohne Argumente.
-func (parser *Parser) IsOpt(long, short string) bool {
-	if parser.opt != long && parser.opt != short {
-		return false
+// Liefert die noch nicht ausgewerteten Argumente.
+func (parser *Parser) Rest() []string {
+	return parser.rest
+}
+
+// Liefert den aktuellen Zustand des Parsers als JSON, z.B. für Fehlerberichte.
+// Kann auch in der Callback-Funktion aufgerufen werden.
+func (parser *Parser) DebugJSON() string {
+	state := struct {
+		Opt      string   `json:"opt"`
+		RawOpt   string   `json:"rawOpt"`
+		StrVal   string   `json:"strVal"`
+		HasVal   bool     `json:"hasVal"`
+		IntVal   int      `json:"intVal"`
+		ArgIdx   int      `json:"argIdx"`
+		Args     []string `json:"args"`
+		Grabbed  bool     `json:"grabbed"`
+		OnlyArgs bool     `json:"onlyArgs"`
+		Rest     []string `json:"rest"`
+	}{
+		Opt:      parser.opt,
+		RawOpt:   parser.rawOpt,
+		StrVal:   parser.strVal,
+		HasVal:   parser.hasVal,
+		IntVal:   parser.intVal,
+		ArgIdx:   parser.argIdx,
+		Args:     parser.args,
+		Grabbed:  parser.grabbed,
+		OnlyArgs: parser.onlyArgs,
+		Rest:     parser.rest,
 	}
 
-	parser.opt = long
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
 
-	if parser.strVal != "" {
-		parser.Errorf("Option erlaubt kein Options-Argument: --%s", parser.opt)
-		return false
+// Entnimmt alle noch nicht ausgewerteten Argumente unverändert (z.B. für
+// "exec -- prog args..."). Ein führendes "--" wird dabei übersprungen
+// (außer mit [KeepDoubleDash]).
+// Die aktuelle Option bzw. das Argument gilt damit als ausgewertet und das
+// Parsen endet ohne Fehler.
+func (parser *Parser) GrabRest() []string {
+	rest := parser.rest
+	if len(rest) > 0 && parser.isOptionTerminator(rest[0]) && !parser.onlyArgs && !parser.config.KeepDoubleDash {
+		rest = rest[1:]
 	}
 
+	parser.rest = nil
 	parser.grabbed = true
-	return true
+	return append([]string{}, rest...)
 }
 
-// Prüft auf Optionen mit einem Argument.
-func (parser *Parser) IsStrOpt(long, short string) bool {
-	if parser.opt != long && parser.opt != short {
-		return false
-	}
-
-	parser.opt = long
+// Prüft, ob nach dem aktuellen Argument noch weitere Argumente folgen.
+func (parser *Parser) HasMore() bool {
+	return len(parser.rest) > 0
+}
 
-	if parser.strVal != "" {
-		parser.grabbed = true
-		return true
+// Entnimmt das nächste noch nicht ausgewertete Argument unverändert, z.B. für
+// eigene Options-Typen mit mehreren Werten. ok ist false, falls kein Argument
+// mehr vorhanden ist. Die aktuelle Option bzw. das Argument gilt damit als ausgewertet.
+func (parser *Parser) NextToken() (token string, ok bool) {
+	if len(parser.rest) == 0 {
+		return "", false
 	}
+	parser.grabbed = true
+	return parser.popNextArg(), true
+}
 
-	if len(parser.rest) > 0 {
-		opt, strVal := parser.parseArg(parser.popNextArg())
-		if opt == "" && strVal != "" {
-			parser.strVal = strVal
-			parser.grabbed = true
-			return true
-		}
-	}
+// Beendet das Parsen nach dem aktuellen Argument ohne Fehler.
+// Die restlichen Argumente können mit [Parser.Rest] abgefragt werden.
+func (parser *Parser) Stop() {
+	parser.stopped = true
+}
 
-	parser.Errorf("Option erwartet ein Options-Argument: --%s", parser.opt)
-	return false
+// Setzt den Parser für neue Argumente (ohne Programm-Namen) zurück, damit er
+// z.B. in einem REPL wiederverwendet werden kann. Alle Werte, Fehler und
+// Zähler des letzten Parsens werden gelöscht, die Einstellungen bleiben erhalten.
+func (parser *Parser) Reset(args []string) {
+	*parser = Parser{rest: args, config: parser.config}
 }
 
-// Liefert das Options-Argument der letzten Option.
-func (parser *Parser) StrVal() string {
-	return parser.strVal
+// Parst die restlichen Argumente mit einer neuen Callback-Funktion, z.B. für Unterkommandos.
+// Das aktuelle Argument gilt als ausgewertet und der Index der Argumente beginnt wieder bei 0.
+// Mit [PosixlyCorrect] werden danach wieder Optionen ausgewertet (bis zum nächsten
+// Argument), nach "--" dagegen nicht.
+//
+//	cmdline.Parse(func(p *cmdline.Parser) {
+//	    switch {
+//	    case p.IsOpt("verbose", "v"):
+//	        verbose = true
+//	    case p.IsCmd("commit"):
+//	        p.ParseRest(func(p *cmdline.Parser) {
+//	            switch {
+//	            case p.IsStrOpt("message", "m"):
+//	                msg = p.StrVal()
+//	            }
+//	        })
+//	    }
+//	})
+func (parser *Parser) ParseRest(fn func(*Parser)) error {
+	parser.grabbed = true
+	parser.onlyArgs = parser.terminated
+	parser.outerFns = append(parser.outerFns, parser.fn)
+	parser.ResetArgIdx()
+	parser.known = nil
+	parser.plusOpts = nil
+	parser.argNames = nil
+	parser.commands = nil
+
+	err := parser.Parse(fn)
+
+	parser.stopped = true
+	return err
 }
 
-// Prüft auf Optionen mit einer Integer-Zahl als Options-Argument.
-// min und max bestimmen den Gültigkeitsbereich.
-func (parser *Parser) IsIntOpt(long, short string, min, max int) bool {
-	if !parser.IsStrOpt(long, short) {
-		return false
+// Übernimmt nach dem Parsen den Wert der Umgebungsvariablen envName für die Option long,
+// falls die Option nicht in der Kommandozeile angegeben wurde (siehe [Parser.Changed]).
+// Der Wert wird wie "--long=Wert" an die Callback-Funktion übergeben und dort
+// entsprechend geprüft. Nach [Parser.ParseRest] (Unterkommandos) wird zuerst die
+// Callback-Funktion des Unterkommandos und dann die äußere Callback-Funktion verwendet.
+//
+//	parser := cmdline.NewParser(os.Args)
+//	err := parser.Parse(fn)
+//	if err == nil {
+//	    err = parser.FromEnv("port", "MYTOOL_PORT")
+//	}
+func (parser *Parser) FromEnv(long, envName string) error {
+	if parser.Changed(long) {
+		return nil
 	}
 
-	parsedVal, err := strconv.ParseInt(parser.strVal, 10, 64)
-	intVal := int(parsedVal)
+	value, ok := os.LookupEnv(envName)
+	if !ok {
+		return nil
+	}
+
+	return parser.parseOptVal(long, value)
+}
 
+// Liest Default-Werte für Optionen aus einer einfachen INI-Datei mit Zeilen
+// der Form "name = Wert" (lange Options-Namen). Leerzeilen, Kommentare
+// ("#" oder ";") und Abschnitte ("[name]") werden ignoriert. Die Werte können
+// mit [Parser.ApplyDefaults] übernommen werden.
+func LoadDefaults(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		parser.Errorf("Ungültige Zahl: %s (Option --%s)", parser.strVal, parser.opt)
-		return false
+		return nil, err
 	}
-	if intVal < min {
-		parser.Errorf("Zahl muß >= %d sein: %d (Option --%s)", min, intVal, parser.opt)
-		return false
-	}
-	if intVal > max {
-		parser.Errorf("Zahl muß <= %d sein: %d (Option --%s)", max, intVal, parser.opt)
-		return false
+
+	defaults := map[string]string{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") ||
+			strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf(Msgs.InvalidDefaultsLine, path, i+1, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		defaults[name] = value
 	}
 
-	parser.intVal = intVal
-	return true
+	return defaults, nil
 }
 
-// Liefert die Zahl der letzen Integer-Option.
-func (parser *Parser) IntVal() int {
-	return parser.intVal
-}
+// Übernimmt nach dem Parsen die Default-Werte (z.B. von [LoadDefaults]) für alle
+// Optionen, die nicht in der Kommandozeile angegeben wurden (siehe [Parser.Changed]).
+// Die Werte werden wie bei [Parser.FromEnv] an die Callback-Funktion übergeben.
+//
+//	parser := cmdline.NewParser(os.Args)
+//	err := parser.Parse(fn)
+//	if err == nil && configFile != "" {
+//	    defaults, err := cmdline.LoadDefaults(configFile)
+//	    if err == nil {
+//	        err = parser.ApplyDefaults(defaults)
+//	    }
+//	}
+func (parser *Parser) ApplyDefaults(defaults map[string]string) error {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if parser.Changed(name) {
+			continue
+		}
+		if err := parser.parseOptVal(name, defaults[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Übergibt die Option long mit dem Options-Argument value an die Callback-Funktion.
+// Nach [Parser.ParseRest] werden auch die äußeren Callback-Funktionen geprüft.
+func (parser *Parser) parseOptVal(long, value string) error {
+	errCount := len(parser.errs)
+
+	// nach [Parser.ParseRest] zuerst die innerste, dann die äußeren Callback-Funktionen
+	fns := []func(*Parser){parser.fn}
+	for i := len(parser.outerFns) - 1; i >= 0; i-- {
+		fns = append(fns, parser.outerFns[i])
+	}
+
+	for _, fn := range fns {
+		if fn == nil {
+			continue
+		}
+		parser.opt, parser.strVal, parser.hasVal = long, value, true
+		parser.rawOpt, parser.plusOpt = "--"+long, false
+		parser.grabbed, parser.strOk, parser.intOk, parser.boolOk = false, false, false, false
+
+		fn(parser)
+
+		// nur Fehler dieses Aufrufs melden, nicht bereits vorher aufgetretene
+		if len(parser.errs) > errCount {
+			return parser.err
+		}
+		if parser.grabbed {
+			return nil
+		}
+	}
+	parser.opt = long
+	return parser.unknownOptError()
+}
+
+// die maximale Verschachtelungstiefe von Argument-Dateien (siehe [ExpandResponseFiles])
+const maxResponseFileDepth = 10
+
+func isResponseFile(arg string) bool {
+	return len(arg) > 1 && strings.HasPrefix(arg, "@")
+}
+
+// Liest die durch Whitespace getrennten Argumente aus der Datei arg ("@file").
+// Verschachtelte Argument-Dateien werden ebenfalls ersetzt.
+func (parser *Parser) readResponseFile(arg string, depth int) ([]string, error) {
+	if depth > maxResponseFileDepth {
+		return nil, parser.optErrorf(OtherError, "", parser.msgs().NestedResponseFiles, arg)
+	}
+
+	data, err := os.ReadFile(arg[1:])
+	if err != nil {
+		return nil, parser.optErrorf(OtherError, "", parser.msgs().UnreadableResponseFile, arg[1:])
+	}
+
+	args := []string{}
+	for _, field := range strings.Fields(string(data)) {
+		if !isResponseFile(field) {
+			args = append(args, field)
+			continue
+		}
+		nested, err := parser.readResponseFile(field, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, nested...)
+	}
+
+	return args, nil
+}
+
+func (parser *Parser) popNextArg() string {
+	if len(parser.rest) == 0 {
+		return ""
+	}
+	arg := parser.rest[0]
+	parser.rest = parser.rest[1:]
+	return arg
+}
+
+// Zerlegt zusammengefasste kurze Optionen wie "-vx" in einzelne Optionen ("-v", "-x").
+// Das ist nur möglich, falls alle Zeichen bekannte kurze Optionen sind und
+// das Argument selbst keine bekannte Option ist. Ein langer Name mit einem "-"
+// (z.B. "-verbose") hat also Vorrang vor zusammengefassten kurzen Optionen.
+// Erwartet eine der kurzen Optionen ein Options-Argument, wird der Rest des
+// Arguments als Options-Argument verwendet ("-ffile.txt" wird zu "-f=file.txt").
+func (parser *Parser) splitShortOpts(arg string) ([]string, bool) {
+	if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") || parser.isNegativeNumber(arg) {
+		return nil, false
+	}
+
+	name := arg[1:]
+	optName, _, _ := strings.Cut(name, "=")
+	if len([]rune(name)) < 2 || parser.findOpt(optName) != nil {
+		return nil, false
+	}
+
+	flags := []string{}
+	for i, r := range name {
+		flag := string(r)
+		info := parser.findOpt(flag)
+		if info == nil || !parser.sameName(flag, info.short) {
+			return nil, false
+		}
+		if info.hasValue {
+			// "-vf=" bleibt ein explizit leeres Options-Argument
+			if value := name[i+len(flag):]; strings.HasPrefix(value, "=") {
+				flag += value
+			} else if value != "" {
+				flag += "=" + value
+			}
+			return append(flags, "-"+flag), true
+		}
+		flags = append(flags, "-"+flag)
+	}
+
+	return flags, true
+}
+
+var negativeNumber = regexp.MustCompile(`^-\d+(\.\d+)?$`)
+
+// Zerlegt ein Argument in Option und Options-Argument.
+// hasVal ist true, falls die Option ein "=" enthält (auch bei leerem Options-Argument).
+func (parser *Parser) parseArg(arg string) (opt, strVal string, hasVal bool) {
+	if parser.isNegativeNumber(arg) || arg == "-" {
+		strVal = arg
+	} else if strings.HasPrefix(arg, "-") || parser.isPlusOpt(arg) {
+		parts := strings.SplitN(strings.TrimLeft(arg, "-+"), "=", 2)
+		opt = parts[0]
+		if opt == "" {
+			opt = "???"
+		}
+		if len(parts) > 1 {
+			strVal = parts[1]
+			hasVal = true
+		}
+	} else {
+		strVal = arg
+	}
+
+	return opt, strVal, hasVal
+}
+
+// Prüft, ob arg eine mit [Parser.IsPlusOpt] abgefragte Option der Form "+name" ist.
+// Alle anderen Argumente mit "+" bleiben normale Argumente.
+func (parser *Parser) isPlusOpt(arg string) bool {
+	name, found := strings.CutPrefix(arg, "+")
+	if !found {
+		return false
+	}
+	name, _, _ = strings.Cut(name, "=")
+
+	parser.knownOpts()
+	for _, known := range parser.plusOpts {
+		if parser.sameName(name, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prüft, ob arg als negative Zahl und nicht als Option behandelt werden soll
+// (siehe [AllowNegativeNumberArgs]).
+func (parser *Parser) isNegativeNumber(arg string) bool {
+	return parser.config.AllowNegativeNumberArgs &&
+		negativeNumber.MatchString(arg) &&
+		parser.findOpt(arg[1:]) == nil
+}
+
+// Gibt entweder eine [SyntaxError]-Meldung auf Stderr aus oder setzt die
+// Fehlermeldung, welche von [Parse] bzw. [ParseArgs] zurückgegeben werden soll.
+// Welche Aktion ausgeführt werden soll bestimmt [ErrorFunc].
+// Der Fehler ist vom Typ [*ParseError] mit der Art [OtherError].
+func (parser *Parser) Errorf(format string, args ...any) error {
+	return parser.errorf(OtherError, format, args...)
+}
+
+// Liefert die Meldungen für die Fehler des Parsers.
+func (parser *Parser) msgs() *Messages {
+	return parser.config.Messages
+}
+
+func (parser *Parser) errorf(kind ErrorKind, format string, args ...any) error {
+	return parser.optErrorf(kind, parser.opt, format, args...)
+}
+
+func (parser *Parser) optErrorf(kind ErrorKind, opt string, format string, args ...any) error {
+	err := &ParseError{
+		Option: opt,
+		Kind:   kind,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+	// im Scan-Modus (siehe [Parser.knownOpts]) wurde nichts ausgewertet
+	if parser.scanning {
+		return err
+	}
+
+	parser.config.ErrorFunc(format, args...)
+	parser.err = err
+	parser.errs = append(parser.errs, parser.err)
+	return parser.err
+}
+
+//--------------------------------------------------------------------------------
+// Fehler
+//--------------------------------------------------------------------------------
+
+// Wird mit [HelpAsError] von [Parse] bzw. [ParseArgs] bei --help zurückgegeben.
+// Kann mit [errors.Is] geprüft werden.
+var ErrHelpRequested = errors.New("help requested")
+
+// Die Art eines [ParseError].
+type ErrorKind int
+
+const (
+	// ein mit [Parser.Errorf] erzeugter Fehler
+	OtherError ErrorKind = iota
+	// unbekannte Option
+	UnknownOption
+	// fehlendes Options-Argument
+	MissingValue
+	// Options-Argument bei einer Option ohne Argument
+	UnexpectedValue
+	// ungültiges Options-Argument
+	InvalidValue
+	// zu viele Argumente
+	TooManyArgs
+	// zu wenige Argumente
+	TooFewArgs
+	// fehlende Pflicht-Option
+	MissingOption
+	// mehrdeutige Abkürzung einer Option
+	AmbiguousOption
+	// Optionen, die nicht zusammen angegeben werden dürfen
+	ConflictingOptions
+	// mehrfach angegebene Option (siehe [RejectDuplicateOptions])
+	DuplicateOption
+)
+
+// Wird von [Parse] bzw. [ParseArgs] bei einem Syntax-Fehler zurückgegeben.
+// Mit [errors.As] können die Details abgefragt werden.
+type ParseError struct {
+	// der lange Name der betroffenen Option (leer bei Argumenten)
+	Option string
+	// die Art des Fehlers
+	Kind ErrorKind
+	// die Fehlermeldung
+	Msg string
+}
+
+func (err *ParseError) Error() string {
+	return err.Msg
+}
+
+//--------------------------------------------------------------------------------
+// Bekannte Optionen
+//--------------------------------------------------------------------------------
+
+// Liefert alle Optionen, auf die in der Callback-Funktion geprüft wird.
+// Dazu wird die Callback-Funktion beim ersten Aufruf einmalig im Scan-Modus
+// aufgerufen, in dem alle Is...-Methoden false liefern und sich nur die
+// Namen der Optionen merken.
+func (parser *Parser) knownOpts() []*optInfo {
+	if parser.known == nil && parser.fn != nil {
+		parser.known = []*optInfo{}
+
+		opt, strVal := parser.opt, parser.strVal
+		parser.opt, parser.strVal = "", ""
+		parser.scanning = true
+
+		parser.fn(parser)
+
+		parser.scanning = false
+		parser.opt, parser.strVal = opt, strVal
+	}
+	return parser.known
+}
+
+// Vermerkt eine Option im Scan-Modus bzw. liefert die bereits vermerkte Option.
+func (parser *Parser) registerOpt(long, short string) *optInfo {
+	for _, info := range parser.known {
+		if info.long == long {
+			if parser.config.CheckOptionNames && short != "" && info.short != "" && short != info.short {
+				panic(fmt.Sprintf("mehrere kurze Namen für Option --%s: -%s, -%s", long, info.short, short))
+			}
+			if info.short == "" {
+				info.short = short
+			}
+			return info
+		}
+	}
+	if parser.config.CheckOptionNames {
+		parser.checkDuplicateName(long, short)
+	}
+	info := &optInfo{long: long, short: short}
+	parser.known = append(parser.known, info)
+	return info
+}
+
+// Beschreibt eine Option für [Parser.BuildHelp].
+// Sollte am Anfang der Callback-Funktion aufgerufen werden:
+//
+//	cmdline.Parse(func(p *cmdline.Parser) {
+//	    p.Flag("verbose", "v", "Verbose Meldungen")
+//	    p.Flag("file", "f", "Datei anzeigen")
+//
+//	    switch {
+//	    case p.IsOpt("verbose", "v"):
+//	    ...
+//	})
+//
+// Die Beschreibung wird nur im Scan-Modus vermerkt, ansonsten hat Flag keine Wirkung.
+func (parser *Parser) Flag(long, short, desc string) {
+	checkShortName(short)
+
+	if parser.scanning {
+		parser.registerOpt(long, short).desc = desc
+	}
+}
+
+// Sucht eine bekannte Option anhand des langen oder kurzen Namens.
+func (parser *Parser) findOpt(name string) *optInfo {
+	for _, info := range parser.knownOpts() {
+		if parser.sameName(name, info.short) {
+			return info
+		}
+		for _, long := range info.longNames() {
+			if parser.sameName(name, long) {
+				return info
+			}
+		}
+	}
+	return nil
+}
+
+// Meldet die aktuelle Option als unbekannt. Gibt es eine ähnliche bekannte
+// Option, wird diese als Vorschlag angehängt.
+func (parser *Parser) unknownOptError() error {
+	msgs := parser.msgs()
+	if similar := parser.similarOpt(parser.opt); similar != "" {
+		return parser.errorf(UnknownOption, msgs.UnknownOption+" "+msgs.DidYouMean, parser.opt, similar)
+	}
+	return parser.errorf(UnknownOption, msgs.UnknownOption, parser.opt)
+}
+
+// Sucht die bekannte Option (langer Name) mit dem kleinsten Abstand zu name.
+// Liefert "", falls keine Option höchstens 2 Zeichen abweicht.
+func (parser *Parser) similarOpt(name string) string {
+	if parser.config.CaseInsensitiveOptions {
+		name = strings.ToLower(name)
+	}
+
+	similar, minDist := "", 3
+	for _, info := range parser.knownOpts() {
+		for _, long := range info.longNames() {
+			known := long
+			if parser.config.CaseInsensitiveOptions {
+				known = strings.ToLower(known)
+			}
+			dist := levenshtein(name, known)
+			if dist < minDist && dist < len([]rune(name)) {
+				similar, minDist = long, dist
+			}
+		}
+	}
+	return similar
+}
+
+// Berechnet die Levenshtein-Distanz zwischen a und b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Ersetzt eine eindeutige Abkürzung der aktuellen Option durch den langen Namen.
+// Ist die Abkürzung mehrdeutig, wird ein Fehler gemeldet.
+func (parser *Parser) expandAbbreviation() error {
+	if parser.opt == "" || parser.findOpt(parser.opt) != nil {
+		return nil
+	}
+
+	matches := []string{}
+	for _, info := range parser.knownOpts() {
+		for _, long := range info.longNames() {
+			if len(parser.opt) <= len(long) && parser.sameName(parser.opt, long[:len(parser.opt)]) {
+				matches = append(matches, info.long)
+				break
+			}
+		}
+	}
+
+	if len(matches) > 1 {
+		return parser.errorf(AmbiguousOption, parser.msgs().AmbiguousOption, parser.opt)
+	}
+	if len(matches) == 1 {
+		parser.opt = matches[0]
+	}
+	return nil
+}
+
+// Vergleicht einen Options-Namen aus der Kommandozeile mit einem bekannten Namen
+// (ggf. ohne Beachtung der Groß-/Kleinschreibung).
+func (parser *Parser) sameName(name, known string) bool {
+	if name == "" || known == "" {
+		return false
+	}
+	if parser.config.CaseInsensitiveOptions {
+		return strings.EqualFold(name, known)
+	}
+	return name == known
+}
+
+// Prüft, ob die aktuelle Option dem langen oder kurzen Namen entspricht,
+// und setzt in diesem Fall den langen Namen als aktuelle Option.
+// Im Scan-Modus wird die Option nur vermerkt.
+func (parser *Parser) matchOpt(long, short string, hasValue bool) bool {
+	checkShortName(short)
+
+	if parser.scanning {
+		info := parser.registerOpt(long, short)
+		info.hasValue = info.hasValue || hasValue
+		return false
+	}
+
+	parser.strOk, parser.intOk = false, false
+
+	if parser.plusOpt {
+		return false
+	}
+	if !parser.sameName(parser.opt, long) && !parser.sameName(parser.opt, short) {
+		return false
+	}
+
+	parser.opt = long
+	return parser.markChanged(long)
+}
+
+// Vermerkt die Option long als angegeben (siehe [Parser.Changed]).
+// Mit [RejectDuplicateOptions] wird ein Fehler gemeldet, falls die Option
+// bereits angegeben wurde und nicht mehrfach angegeben werden darf.
+func (parser *Parser) markChanged(long string) bool {
+	if parser.config.RejectDuplicateOptions && parser.Changed(long) {
+		if info := parser.findOpt(long); info == nil || !info.repeatable {
+			parser.errorf(DuplicateOption, parser.msgs().DuplicateOption, long)
+			return false
+		}
+	}
+	parser.setChanged(long)
+	return true
+}
+
+// Löst eine panic aus, falls der kurze Name einer Option aus mehr als einem
+// Zeichen besteht, da es sich um einen Programmierfehler handelt.
+func checkShortName(short string) {
+	if len([]rune(short)) > 1 {
+		panic("short option muss ein Zeichen sein: " + short)
+	}
+}
+
+// Löst eine panic aus, falls der lange oder kurze Name einer neuen Option
+// bereits von einer anderen Option verwendet wird (siehe [CheckOptionNames]).
+func (parser *Parser) checkDuplicateName(long, short string) {
+	for _, info := range parser.known {
+		for _, known := range info.longNames() {
+			if parser.sameName(long, known) {
+				panic(fmt.Sprintf("Option --%s mehrfach verwendet", long))
+			}
+		}
+		if parser.sameName(short, info.short) {
+			panic(fmt.Sprintf("kurze Option -%s für --%s und --%s verwendet", short, info.long, long))
+		}
+	}
+}
+
+// Vermerkt im Scan-Modus, daß die Option mehrfach angegeben werden darf
+// (siehe [RejectDuplicateOptions]).
+func (parser *Parser) setRepeatable(long, short string) {
+	if parser.scanning {
+		parser.registerOpt(long, short).repeatable = true
+	}
+}
+
+func (parser *Parser) setChanged(long string) {
+	if parser.changed == nil {
+		parser.changed = map[string]bool{}
+	}
+	parser.changed[long] = true
+}
+
+// Prüft, ob die Option (langer Name) in der Kommandozeile angegeben wurde.
+// Siehe auch [Parser.Require].
+// Damit läßt sich z.B. ein explizit leerer Wert vom Default-Wert unterscheiden.
+func (parser *Parser) Changed(long string) bool {
+	return parser.changed[long]
+}
+
+// Eine in der Kommandozeile angegebene Option (siehe [Parser.ProvidedOptions]).
+type ProvidedOption struct {
+	// der lange Name der Option
+	Name string
+	// das Options-Argument (leer bei Optionen ohne Argument, bei [Parser.IsBoolOpt]
+	// der ausgewertete Wert "true" bzw. "false", z.B. "false" für --no-verbose)
+	Value string
+}
+
+// Liefert nach dem Parsen alle erfolgreich ausgewerteten Optionen in der
+// Reihenfolge der Kommandozeile (z.B. für ein Protokoll).
+// Werte aus [Parser.FromEnv] und [Parser.ApplyDefaults] sind nicht enthalten.
+func (parser *Parser) ProvidedOptions() []ProvidedOption {
+	return parser.provided
+}
+
+// Prüft nach dem Parsen, ob alle übergebenen Optionen (lange Namen) angegeben wurden.
+// Für die erste fehlende Option wird ein Fehler über [Parser.Errorf] gemeldet.
+func (parser *Parser) Require(longNames ...string) error {
+	for _, long := range longNames {
+		if !parser.Changed(long) {
+			return parser.optErrorf(MissingOption, long, parser.msgs().MissingOption, long)
+		}
+	}
+	return nil
+}
+
+// Prüft nach dem Parsen, ob höchstens eine der übergebenen Optionen (lange Namen)
+// angegeben wurde. Für mehrere unabhängige Gruppen kann MutuallyExclusive
+// mehrfach aufgerufen werden.
+func (parser *Parser) MutuallyExclusive(longNames ...string) error {
+	first := ""
+	for _, long := range longNames {
+		if !parser.Changed(long) {
+			continue
+		}
+		if first != "" {
+			return parser.optErrorf(ConflictingOptions, long, parser.msgs().ConflictingOptions, first, long)
+		}
+		first = long
+	}
+	return nil
+}
+
+// Prüft nach dem Parsen, ob alle in needs übergebenen Optionen angegeben wurden,
+// falls die Option option angegeben wurde (z.B. --cert benötigt --key).
+func (parser *Parser) Requires(option string, needs ...string) error {
+	if !parser.Changed(option) {
+		return nil
+	}
+	for _, long := range needs {
+		if !parser.Changed(long) {
+			return parser.optErrorf(MissingOption, long, parser.msgs().DependentOption, option, long)
+		}
+	}
+	return nil
+}
+
+//--------------------------------------------------------------------------------
+// Argumente/Optionen prüfen
+//--------------------------------------------------------------------------------
+
+// Liefert den langen Namen der aktuellen Option (leer bei Argumenten).
+func (parser *Parser) Opt() string {
+	return parser.opt
+}
+
+// Liefert die aktuelle Option so, wie sie in der Kommandozeile angegeben wurde
+// (z.B. "-f" oder "--file", ohne Options-Argument). Siehe auch [Parser.Opt].
+func (parser *Parser) RawOpt() string {
+	return parser.rawOpt
+}
+
+// Prüft auf Optionen ohne Argumente.
+// Mehrere kurze Optionen können auch zusammengefasst werden (z.B. "-vx" statt "-v -x").
+// Ein Wert wie "--verbose=0" ist ein Fehler. Soll die Option auch explizit
+// ein- bzw. ausgeschaltet werden können, muß [Parser.IsBoolOpt] verwendet werden.
+func (parser *Parser) IsOpt(long, short string) bool {
+	if !parser.matchOpt(long, short, false) {
+		return false
+	}
+
+	if parser.hasVal {
+		parser.errorf(UnexpectedValue, parser.msgs().UnexpectedValue, parser.opt)
+		return false
+	}
+
+	parser.grabbed = true
+	return true
+}
+
+// Prüft auf Optionen ohne Argumente (Flags). Gleichbedeutend mit [Parser.IsOpt],
+// macht aber deutlich, daß die Option kein Options-Argument hat.
+func (parser *Parser) IsFlag(long, short string) bool {
+	return parser.IsOpt(long, short)
+}
+
+// Prüft auf Optionen der Form "+name" ohne Argumente (z.B. "+v" als Gegenteil von "-v").
+// Diese werden nicht mit "-v" bzw. "--verbose" verwechselt und nur erkannt, falls
+// sie in der Callback-Funktion abgefragt werden.
+func (parser *Parser) IsPlusOpt(long, short string) bool {
+	checkShortName(short)
+
+	if parser.scanning {
+		parser.plusOpts = append(parser.plusOpts, long)
+		if short != "" {
+			parser.plusOpts = append(parser.plusOpts, short)
+		}
+		return false
+	}
+
+	if !parser.plusOpt || (!parser.sameName(parser.opt, long) && !parser.sameName(parser.opt, short)) {
+		return false
+	}
+
+	parser.opt = long
+	if !parser.markChanged(long) {
+		return false
+	}
+
+	if parser.hasVal {
+		parser.errorf(UnexpectedValue, parser.msgs().UnexpectedValue, parser.opt)
+		return false
+	}
+
+	parser.grabbed = true
+	return true
+}
+
+// Prüft auf Optionen ohne Argumente, die mehrfach angegeben werden können
+// (z.B. "-v -v -v" oder "-vvv").
+// Die Anzahl wird über alle Argumente hinweg gezählt.
+func (parser *Parser) IsCountOpt(long, short string) bool {
+	parser.setRepeatable(long, short)
+
+	if !parser.IsOpt(long, short) {
+		return false
+	}
+
+	if parser.counts == nil {
+		parser.counts = map[string]int{}
+	}
+	parser.counts[long]++
+	parser.countVal = parser.counts[long]
+	return true
+}
+
+// Liefert, wie oft die letzte Zähl-Option bisher angegeben wurde.
+func (parser *Parser) CountVal() int {
+	return parser.countVal
+}
+
+// Prüft auf Wahrheitswert-Optionen, die auch negiert werden können.
+// "--verbose" liefert true, "--no-verbose" liefert false.
+// Die lange Form erlaubt auch einen expliziten Wert ("--verbose=false"),
+// die kurze Form und die negierte Form erlauben keinen Wert.
+// Als Wert sind true/false, yes/no, on/off, 1/0 und enabled/disabled erlaubt
+// (ohne Beachtung der Groß-/Kleinschreibung).
+func (parser *Parser) IsBoolOpt(long, short string) bool {
+	if !parser.scanning && parser.sameName(parser.opt, "no-"+long) {
+		if parser.hasVal {
+			parser.errorf(UnexpectedValue, parser.msgs().UnexpectedValue, parser.opt)
+			return false
+		}
+		parser.opt = long
+		if !parser.markChanged(long) {
+			return false
+		}
+		parser.boolVal = false
+		parser.grabbed, parser.boolOk = true, true
+		return true
+	}
+
+	isShort := parser.sameName(parser.opt, short)
+
+	if !parser.matchOpt(long, short, false) {
+		return false
+	}
+
+	if !parser.hasVal {
+		parser.boolVal = true
+		parser.grabbed, parser.boolOk = true, true
+		return true
+	}
+
+	if isShort {
+		parser.errorf(UnexpectedValue, parser.msgs().UnexpectedValue, parser.opt)
+		return false
+	}
+
+	boolVal, ok := parseBool(parser.strVal)
+	if !ok {
+		parser.errorf(InvalidValue, parser.msgs().InvalidBool, parser.strVal, parser.opt)
+		return false
+	}
+
+	parser.boolVal = boolVal
+	parser.grabbed, parser.boolOk = true, true
+	return true
+}
+
+// Liefert den Wert der letzten Wahrheitswert-Option.
+func (parser *Parser) BoolVal() bool {
+	return parser.boolVal
+}
+
+// Parst einen Wahrheitswert ohne Beachtung der Groß-/Kleinschreibung.
+func parseBool(s string) (value, ok bool) {
+	switch strings.ToLower(s) {
+	case "true", "t", "yes", "y", "on", "1", "enabled":
+		return true, true
+	case "false", "f", "no", "n", "off", "0", "disabled":
+		return false, true
+	}
+	return false, false
+}
+
+// Prüft auf Optionen mit einem Argument.
+// Mit "--opt=" kann auch ein explizit leeres Options-Argument angegeben werden.
+// Ein folgendes "--" wird als Options-Argument übernommen (z.B. "--sep --")
+// und beendet nicht die Auswertung der Optionen.
+func (parser *Parser) IsStrOpt(long, short string) bool {
+	return parser.isStrOpt(long, short, false)
+}
+
+// Prüft auf Optionen mit einem Argument (siehe [Parser.IsStrOpt]).
+// Mit numeric wird ein folgendes Argument wie "-5" als negative Zahl übernommen.
+func (parser *Parser) isStrOpt(long, short string, numeric bool) bool {
+	if !parser.matchOpt(long, short, true) {
+		return false
+	}
+
+	if !parser.hasVal {
+		if len(parser.rest) == 0 {
+			parser.errorf(MissingValue, parser.msgs().MissingValue, parser.opt)
+			return false
+		}
+
+		next := parser.popNextArg()
+		opt, strVal, _ := parser.parseArg(next)
+		if next == "--" || parser.isOptionTerminator(next) || numeric && negativeNumber.MatchString(next) {
+			opt, strVal = "", next
+		}
+		if opt != "" {
+			// die Option wird danach noch ausgewertet (z.B. für [CollectAllErrors])
+			parser.rest = append([]string{next}, parser.rest...)
+			parser.errorf(MissingValue, parser.msgs().OptionInsteadOfValue, parser.opt, next)
+			return false
+		}
+		if strVal == "" {
+			parser.errorf(MissingValue, parser.msgs().MissingValue, parser.opt)
+			return false
+		}
+		parser.strVal = strVal
+	}
+
+	if !parser.checkValueLen() {
+		return false
+	}
+
+	parser.grabbed, parser.strOk = true, true
+	return true
+}
+
+// Prüft die Länge des Options-Arguments (siehe [MaxOptValueLen]).
+func (parser *Parser) checkValueLen() bool {
+	max := parser.config.MaxOptValueLen
+	if max > 0 && len([]rune(parser.strVal)) > max {
+		parser.errorf(InvalidValue, parser.msgs().ValueTooLong, max, parser.opt)
+		return false
+	}
+	return true
+}
+
+// Prüft auf Optionen mit einem optionalen Argument (z.B. "--color" oder
+// "--color=always"). Der Wert muß direkt mit "=" angehängt werden, das nächste
+// Argument wird nie übernommen. Fehlt der Wert, liefert [Parser.StrVal] ifAbsent.
+func (parser *Parser) IsOptionalStrOpt(long, short string, ifAbsent string) bool {
+	if !parser.matchOpt(long, short, true) {
+		return false
+	}
+
+	if !parser.hasVal {
+		parser.strVal = ifAbsent
+	} else if !parser.checkValueLen() {
+		return false
+	}
+
+	parser.grabbed, parser.strOk = true, true
+	return true
+}
+
+// Prüft auf Optionen mit zwei Options-Argumenten (z.B. "--range 10 20").
+// Die Werte können auch mit Komma getrennt angehängt werden ("--range=10,20").
+// Enthält ein angehängter Wert kein Komma, wird der zweite Wert aus dem nächsten
+// Argument übernommen ("--range=10 20"). Der erste Wert wird mit [Parser.StrVal],
+// der zweite mit [Parser.SecondVal] abgefragt.
+func (parser *Parser) IsPairOpt(long, short string) bool {
+	if !parser.matchOpt(long, short, true) {
+		return false
+	}
+
+	values := []string{}
+	if parser.hasVal {
+		first, second, found := strings.Cut(parser.strVal, ",")
+		values = append(values, first)
+		if found {
+			values = append(values, second)
+		}
+	}
+
+	for len(values) < 2 && len(parser.rest) > 0 {
+		opt, strVal, _ := parser.parseArg(parser.rest[0])
+		if opt != "" || strVal == "" {
+			break
+		}
+		parser.popNextArg()
+		values = append(values, strVal)
+	}
+
+	if len(values) < 2 {
+		parser.errorf(MissingValue, parser.msgs().MissingValue, parser.opt)
+		return false
+	}
+
+	parser.strVal, parser.secondVal = values[0], values[1]
+	parser.grabbed, parser.strOk = true, true
+	return true
+}
+
+// Liefert den zweiten Wert der letzten Option (siehe [Parser.IsPairOpt]).
+func (parser *Parser) SecondVal() string {
+	return parser.secondVal
+}
+
+// Prüft auf Optionen mit einem Argument wie [Parser.IsStrOpt], die mehrere Namen
+// haben (z.B. "--output" und "--out"). Der erste Name ist der eigentliche Name
+// der Option, der auch in Fehlermeldungen und für [Parser.Changed] verwendet wird.
+// Namen mit einem Zeichen werden als kurze Option erkannt ("-o").
+func (parser *Parser) IsStrOptAlias(names []string) bool {
+	if len(names) == 0 {
+		panic("IsStrOptAlias: keine Namen angegeben")
+	}
+
+	short, aliases := "", []string{}
+	for _, name := range names[1:] {
+		if short == "" && len([]rune(name)) == 1 {
+			short = name
+		} else {
+			aliases = append(aliases, name)
+		}
+	}
+
+	if parser.scanning {
+		parser.registerAliases(names[0], short, aliases)
+	} else if !parser.plusOpt {
+		for _, name := range names[1:] {
+			if parser.sameName(parser.opt, name) {
+				parser.opt = names[0]
+				break
+			}
+		}
+	}
+
+	return parser.IsStrOpt(names[0], short)
+}
+
+// Prüft, ob list den String s enthält.
+func containsStr(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Vermerkt im Scan-Modus die weiteren Namen einer Option (siehe [Parser.IsStrOptAlias]).
+func (parser *Parser) registerAliases(long, short string, aliases []string) {
+	info := parser.registerOpt(long, short)
+	for _, alias := range aliases {
+		if containsStr(info.aliases, alias) {
+			continue
+		}
+		if parser.config.CheckOptionNames {
+			parser.checkDuplicateName(alias, "")
+		}
+		info.aliases = append(info.aliases, alias)
+	}
+}
+
+// Prüft auf Optionen mit einem Argument wie [Parser.IsStrOpt], übernimmt aber
+// das nächste Argument immer als Options-Argument, auch wenn es mit "-" beginnt
+// (z.B. "--prefix -x"). Die Prüfung, ob das Argument wie eine Option aussieht,
+// entfällt damit.
+func (parser *Parser) IsStrOptGreedy(long, short string) bool {
+	if !parser.matchOpt(long, short, true) {
+		return false
+	}
+
+	if !parser.hasVal {
+		if len(parser.rest) == 0 {
+			parser.errorf(MissingValue, parser.msgs().MissingValue, parser.opt)
+			return false
+		}
+		parser.strVal = parser.popNextArg()
+	}
+
+	if !parser.checkValueLen() {
+		return false
+	}
+
+	parser.grabbed, parser.strOk = true, true
+	return true
+}
+
+// Liefert das Options-Argument der letzten Option.
+func (parser *Parser) StrVal() string {
+	return parser.strVal
+}
+
+// Liefert das Options-Argument wie [Parser.StrVal]. ok ist nur true, falls die
+// zuletzt geprüfte Option erfolgreich mit einem Options-Argument ausgewertet wurde.
+func (parser *Parser) StrValOk() (value string, ok bool) {
+	return parser.strVal, parser.strOk
+}
+
+// Prüft auf Optionen mit einem Argument wie [Parser.IsStrOpt] und prüft das
+// Options-Argument zusätzlich mit der Funktion validate.
+// Liefert validate einen Fehler, wird dieser mit dem Namen der Option gemeldet.
+func (parser *Parser) IsStrOptFunc(long, short string, validate func(string) error) bool {
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	if err := validate(parser.strVal); err != nil {
+		parser.errorf(InvalidValue, parser.msgs().ValidationFailed, err, parser.opt)
+		return false
+	}
+	return true
+}
+
+// Prüft auf Optionen mit einem Argument, die mehrfach angegeben werden können
+// (z.B. "-I path1 -I path2"). Die Werte werden über alle Argumente hinweg gesammelt.
+func (parser *Parser) IsStrSliceOpt(long, short string) bool {
+	parser.setRepeatable(long, short)
+
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	if parser.slices == nil {
+		parser.slices = map[string][]string{}
+	}
+	parser.slices[long] = append(parser.slices[long], parser.strVal)
+	return true
+}
+
+// Liefert alle bisherigen Werte der letzten Listen-Option.
+func (parser *Parser) StrSliceVal() []string {
+	return parser.slices[parser.opt]
+}
+
+// Prüft auf Optionen mit einem Schlüssel-Wert-Paar als Options-Argument
+// (z.B. "-D key=value"), die mehrfach angegeben werden können.
+// Die Paare werden über alle Argumente hinweg gesammelt, spätere Schlüssel
+// überschreiben frühere. Ohne "=" (z.B. "-D FOO") ist der Wert leer.
+func (parser *Parser) IsMapOpt(long, short string) bool {
+	parser.setRepeatable(long, short)
+
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	if parser.maps == nil {
+		parser.maps = map[string]map[string]string{}
+	}
+	if parser.maps[long] == nil {
+		parser.maps[long] = map[string]string{}
+	}
+
+	key, value, _ := strings.Cut(parser.strVal, "=")
+	parser.maps[long][key] = value
+	return true
+}
+
+// Liefert alle bisherigen Schlüssel-Wert-Paare der letzten Map-Option.
+func (parser *Parser) MapVal() map[string]string {
+	return parser.maps[parser.opt]
+}
+
+// Prüft auf Optionen mit einer komma-separierten Liste als Options-Argument
+// (z.B. "--tags=a,b,c"). Leerzeichen um die Elemente und leere Elemente werden entfernt.
+// Ein Komma im Wert wird mit "\," angegeben (z.B. "--tags=a\,b,c").
+func (parser *Parser) IsCsvOpt(long, short string) bool {
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	parser.csvVal = splitCsv(parser.strVal)
+	return true
+}
+
+// Liefert die Elemente der letzten Listen-Option.
+func (parser *Parser) CsvVal() []string {
+	return parser.csvVal
+}
+
+// Zerlegt eine komma-separierte Liste. Leere Elemente werden ignoriert.
+// Mit "\," kann ein Komma und mit "\\" ein Backslash im Wert angegeben werden,
+// andere Backslashes bleiben unverändert.
+func splitCsv(s string) []string {
+	values := []string{}
+	value := strings.Builder{}
+
+	add := func() {
+		if v := strings.TrimSpace(value.String()); v != "" {
+			values = append(values, v)
+		}
+		value.Reset()
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && (runes[i+1] == ',' || runes[i+1] == '\\'):
+			i++
+			value.WriteRune(runes[i])
+		case r == ',':
+			add()
+		default:
+			value.WriteRune(r)
+		}
+	}
+	add()
+
+	return values
+}
+
+// Prüft auf Optionen, deren Options-Argument einer der übergebenen Werte sein muß
+// (z.B. "--color=always|never|auto"). Groß-/Kleinschreibung wird beachtet.
+// Der Wert kann mit [Parser.StrVal] abgefragt werden.
+// Ohne Werte wird eine panic ausgelöst, da es sich um einen Programmierfehler handelt.
+func (parser *Parser) IsEnumOpt(long, short string, choices ...string) bool {
+	if len(choices) == 0 {
+		panic("IsEnumOpt: keine Werte für Option --" + long)
+	}
+
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	for _, choice := range choices {
+		if parser.strVal == choice {
+			return true
+		}
+	}
+
+	parser.errorf(InvalidValue, parser.msgs().InvalidChoice, parser.strVal, parser.opt, strings.Join(choices, ", "))
+	return false
+}
+
+// Prüft auf Optionen mit einer Integer-Zahl als Options-Argument.
+// min und max bestimmen den Gültigkeitsbereich.
+// Die Präfixe 0x (hexadezimal), 0o (oktal) und 0b (binär) werden unterstützt.
+// Achtung: eine führende 0 bedeutet ebenfalls oktal, d.h. "0755" ergibt 493.
+// Negative Zahlen können auch als eigenes Argument angegeben werden ("-l -2").
+func (parser *Parser) IsIntOpt(long, short string, min, max int) bool {
+	if !parser.isStrOpt(long, short, true) {
+		return false
+	}
+
+	intVal, ok := parser.parseInt(parser.strVal, min, max)
+	if !ok {
+		return false
+	}
+
+	parser.intVal, parser.intOk = intVal, true
+	return true
+}
+
+// Prüft auf Optionen mit einer Integer-Zahl >= min (siehe [Parser.IsIntOpt]).
+func (parser *Parser) IsIntOptMin(long, short string, min int) bool {
+	return parser.IsIntOpt(long, short, min, math.MaxInt)
+}
+
+// Prüft auf Optionen mit einer Integer-Zahl <= max (siehe [Parser.IsIntOpt]).
+func (parser *Parser) IsIntOptMax(long, short string, max int) bool {
+	return parser.IsIntOpt(long, short, math.MinInt, max)
+}
+
+// Prüft auf Optionen mit einer beliebigen Integer-Zahl (siehe [Parser.IsIntOpt]).
+func (parser *Parser) IsAnyIntOpt(long, short string) bool {
+	return parser.IsIntOpt(long, short, math.MinInt, math.MaxInt)
+}
+
+// Parst eine Integer-Zahl für die aktuelle Option und prüft den Gültigkeitsbereich.
+func (parser *Parser) parseInt(s string, min, max int) (int, bool) {
+	parsedVal, err := strconv.ParseInt(s, 0, 64)
+	intVal := int(parsedVal)
+
+	if err != nil {
+		parser.errorf(InvalidValue, parser.msgs().InvalidNumber, s, parser.opt)
+		return 0, false
+	}
+	if intVal < min {
+		parser.errorf(InvalidValue, parser.msgs().NumberTooSmall, min, intVal, parser.opt)
+		return 0, false
+	}
+	if intVal > max {
+		parser.errorf(InvalidValue, parser.msgs().NumberTooLarge, max, intVal, parser.opt)
+		return 0, false
+	}
+
+	return intVal, true
+}
+
+// Liefert die Zahl der letzen Integer-Option.
+func (parser *Parser) IntVal() int {
+	return parser.intVal
+}
+
+// Liefert die Zahl wie [Parser.IntVal]. ok ist nur true, falls die zuletzt
+// geprüfte Option eine gültige Integer-Option war.
+func (parser *Parser) IntValOk() (value int, ok bool) {
+	return parser.intVal, parser.intOk
+}
+
+// Prüft auf Optionen mit Integer-Zahlen als Options-Argument, die mehrfach
+// angegeben werden können ("--id 1 --id 2") oder komma-separiert ("--id=1,2").
+// Die Zahlen werden über alle Argumente hinweg gesammelt.
+// min und max bestimmen den Gültigkeitsbereich jeder Zahl.
+func (parser *Parser) IsIntSliceOpt(long, short string, min, max int) bool {
+	parser.setRepeatable(long, short)
+
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	values := []int{}
+	for _, s := range splitCsv(parser.strVal) {
+		intVal, ok := parser.parseInt(s, min, max)
+		if !ok {
+			return false
+		}
+		values = append(values, intVal)
+	}
+
+	if parser.intSlices == nil {
+		parser.intSlices = map[string][]int{}
+	}
+	parser.intSlices[long] = append(parser.intSlices[long], values...)
+	return true
+}
+
+// Liefert alle bisherigen Zahlen der letzten Integer-Listen-Option.
+func (parser *Parser) IntSliceVal() []int {
+	return parser.intSlices[parser.opt]
+}
+
+// Prüft auf Optionen mit einer nicht-negativen Integer-Zahl als Options-Argument.
+// max bestimmt die Obergrenze. Präfixe werden wie bei [Parser.IsIntOpt] unterstützt.
+func (parser *Parser) IsUintOpt(long, short string, max uint64) bool {
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	if strings.HasPrefix(parser.strVal, "-") {
+		parser.errorf(InvalidValue, parser.msgs().NegativeNumber, parser.strVal, parser.opt)
+		return false
+	}
+
+	uintVal, err := strconv.ParseUint(parser.strVal, 0, 64)
+
+	if err != nil {
+		parser.errorf(InvalidValue, parser.msgs().InvalidNumber, parser.strVal, parser.opt)
+		return false
+	}
+	if uintVal > max {
+		parser.errorf(InvalidValue, parser.msgs().NumberTooLarge, max, uintVal, parser.opt)
+		return false
+	}
+
+	parser.uintVal = uintVal
+	return true
+}
+
+// Liefert die Zahl der letzten vorzeichenlosen Integer-Option.
+func (parser *Parser) UintVal() uint64 {
+	return parser.uintVal
+}
+
+// Prüft auf Optionen mit einer Fließkomma-Zahl als Options-Argument.
+// min und max bestimmen den Gültigkeitsbereich.
+// Die Zahl kann auch in Exponential-Schreibweise (z.B. 1e3) angegeben werden.
+// Negative Zahlen können auch als eigenes Argument angegeben werden ("-t -0.5").
+func (parser *Parser) IsFloatOpt(long, short string, min, max float64) bool {
+	if !parser.isStrOpt(long, short, true) {
+		return false
+	}
+
+	floatVal, err := strconv.ParseFloat(parser.strVal, 64)
+
+	if err != nil {
+		parser.errorf(InvalidValue, parser.msgs().InvalidNumber, parser.strVal, parser.opt)
+		return false
+	}
+	if floatVal < min {
+		parser.errorf(InvalidValue, parser.msgs().NumberTooSmall, min, floatVal, parser.opt)
+		return false
+	}
+	if floatVal > max {
+		parser.errorf(InvalidValue, parser.msgs().NumberTooLarge, max, floatVal, parser.opt)
+		return false
+	}
+
+	parser.floatVal = floatVal
+	return true
+}
+
+// Liefert die Zahl der letzten Fließkomma-Option.
+func (parser *Parser) FloatVal() float64 {
+	return parser.floatVal
+}
+
+// Prüft auf Optionen mit einer Größe in Bytes als Options-Argument (z.B. "10MB").
+// Erlaubt sind die Einheiten B, K/KB, M/MB, G/GB, T/TB (Faktor 1000) und
+// KiB, MiB, GiB, TiB (Faktor 1024). Ohne Einheit ist die Zahl in Bytes.
+func (parser *Parser) IsSizeOpt(long, short string) bool {
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	size, ok := parseSize(parser.strVal)
+	if !ok {
+		parser.errorf(InvalidValue, parser.msgs().InvalidSize, parser.strVal, parser.opt)
+		return false
+	}
+
+	parser.sizeVal = size
+	return true
+}
+
+// Liefert die Größe der letzten Größen-Option in Bytes.
+func (parser *Parser) SizeVal() int64 {
+	return parser.sizeVal
+}
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"B":   1,
+	"K":   1e3,
+	"KB":  1e3,
+	"M":   1e6,
+	"MB":  1e6,
+	"G":   1e9,
+	"GB":  1e9,
+	"T":   1e12,
+	"TB":  1e12,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+func parseSize(s string) (int64, bool) {
+	numLen := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if numLen < 0 {
+		numLen = len(s)
+	}
+
+	num, err := strconv.ParseFloat(s[:numLen], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	unit, ok := sizeUnits[strings.ToUpper(s[numLen:])]
+	if !ok || num*unit > math.MaxInt64 {
+		return 0, false
+	}
+
+	return int64(num * unit), true
+}
+
+// Prüft auf Optionen mit einer Zeitdauer als Options-Argument (z.B. "30s" oder "1h30m").
+// Das Format entspricht [time.ParseDuration].
+func (parser *Parser) IsDurationOpt(long, short string) bool {
+	if !parser.IsStrOpt(long, short) {
+		return false
+	}
+
+	duration, err := time.ParseDuration(parser.strVal)
+	if err != nil {
+		parser.errorf(InvalidValue, parser.msgs().InvalidDuration, parser.strVal, parser.opt)
+		return false
+	}
+
+	parser.duration = duration
+	return true
+}
+
+// Liefert die Zeitdauer der letzten Dauer-Option.
+func (parser *Parser) DurationVal() time.Duration {
+	return parser.duration
+}
 
 // Prüft auf ein beliebiges Argument ohne bestimmten Index.
 func (parser *Parser) IsArg() bool {
@@ -343,11 +2438,34 @@ func (parser *Parser) IsArg() bool {
 	return false
 }
 
+// Prüft, ob das erste Argument das Unterkommando name ist.
+// Das Argument gilt damit als ausgewertet. Die Argumente und Optionen des
+// Unterkommandos können mit [Parser.ParseRest] geparst werden.
+func (parser *Parser) IsCmd(name string) bool {
+	if parser.scanning {
+		parser.commands = append(parser.commands, name)
+		return false
+	}
+
+	if !parser.IsArgN(0) || parser.strVal != name {
+		return false
+	}
+
+	parser.Arg()
+	return true
+}
+
 // Liefert den Index des aktuellen Arguments
 func (parser *Parser) ArgIdx() int {
 	return parser.argIdx
 }
 
+// Setzt den Index der Argumente zurück, damit z.B. nach einem Unterkommando
+// das nächste Argument wieder den Index 0 hat (siehe auch [Parser.ParseRest]).
+func (parser *Parser) ResetArgIdx() {
+	parser.argIdx = 0
+}
+
 // Prüft auf ein Argument mit einem bestimmten Index.
 // Das erste Argument hat den Index 0.
 func (parser *Parser) IsArgN(idx int) bool {
@@ -357,11 +2475,150 @@ func (parser *Parser) IsArgN(idx int) bool {
 	return parser.IsArg()
 }
 
+// Prüft auf ein Argument mit einem bestimmten Index wie [Parser.IsArgN] und vermerkt
+// dessen Namen (z.B. "SOURCE") für die Fehlermeldung von [Parser.ExpectArgs].
+// Der Name ist nur bekannt, falls IsNamedArg beim Parsen ausgewertet wurde (also
+// vor dem passenden case-Zweig einer Option bzw. eines Arguments steht) oder die
+// Callback-Funktion im Scan-Modus aufgerufen wurde (siehe [Parser.Parse]).
+func (parser *Parser) IsNamedArg(idx int, name string) bool {
+	if parser.argNames == nil {
+		parser.argNames = map[int]string{}
+	}
+	parser.argNames[idx] = name
+
+	if parser.scanning {
+		return false
+	}
+	return parser.IsArgN(idx)
+}
+
+// Prüft auf ein Argument, dessen Index im Bereich [from, to) liegt.
+func (parser *Parser) IsArgRange(from, to int) bool {
+	if parser.argIdx < from || parser.argIdx >= to {
+		return false
+	}
+	return parser.IsArg()
+}
+
+// Prüft auf das Argument "-", das üblicherweise für Stdin (bzw. Stdout) steht.
+func (parser *Parser) IsStdinArg() bool {
+	return parser.IsArg() && parser.strVal == "-"
+}
+
 // Liefert das letzte Argument.
 func (parser *Parser) Arg() string {
 	if !parser.grabbed {
 		parser.grabbed = true
 		parser.argIdx++
+		parser.args = append(parser.args, parser.strVal)
+		if parser.config.PosixlyCorrect {
+			parser.onlyArgs = true
+		}
 	}
 	return parser.strVal
 }
+
+// Liefert alle bisher mit [Parser.Arg] ausgewerteten Argumente (ohne Options-Argumente).
+// Im Gegensatz zu [Parser.ArgIdx] zählen hier auch Unterkommandos und die
+// Argumente, die mit [Parser.ParseRest] ausgewertet wurden (Anzahl: len(p.Args())).
+func (parser *Parser) Args() []string {
+	return parser.args
+}
+
+// Prüft nach dem Parsen die Anzahl der mit [Parser.Arg] ausgewerteten Argumente
+// (wie [Parser.ArgIdx], nach [Parser.ParseRest] also die des Unterkommandos).
+// max = -1 bedeutet unbegrenzt.
+// Hat das erste fehlende Argument einen Namen (siehe [Parser.IsNamedArg]),
+// wird dieser in der Fehlermeldung genannt.
+func (parser *Parser) ExpectArgs(min, max int) error {
+	n := parser.argIdx
+	if n < min {
+		if name, ok := parser.argNames[n]; ok {
+			return parser.optErrorf(TooFewArgs, "", parser.msgs().MissingArg, name)
+		}
+		return parser.optErrorf(TooFewArgs, "", parser.msgs().TooFewArgsMin, min)
+	}
+	if max >= 0 && n > max {
+		return parser.optErrorf(TooManyArgs, "", parser.msgs().TooManyArgsMax, max)
+	}
+	return nil
+}
+
+//--------------------------------------------------------------------------------
+// Hilfe
+//--------------------------------------------------------------------------------
+
+// die maximale Zeilenlänge von [Parser.BuildHelp]
+const helpWidth = 80
+
+// Erzeugt die Options-Zeilen für den Hilfe-Text aus den bekannten Optionen
+// und den mit [Parser.Flag] vermerkten Beschreibungen.
+// Die Zeilen beginnen mit [FormatHelpPrefix], damit sie direkt in [Help] verwendet werden können
+// (siehe [FormatHelp]). Lange Beschreibungen werden umgebrochen.
+// Falls [Help] leer ist, wird für --help automatisch ein Hilfe-Text erzeugt.
+func (parser *Parser) BuildHelp() string {
+	known := parser.knownOpts()
+
+	names := make([]string, len(known))
+	nameWidth := 0
+
+	for i, info := range known {
+		name := "    "
+		if info.short != "" {
+			name = "-" + info.short + ", "
+		}
+		name += "--" + info.long
+		for _, alias := range info.aliases {
+			name += ", --" + alias
+		}
+		if info.hasValue {
+			name += "=" + strings.ToUpper(info.long)
+		}
+		names[i] = name
+		if len([]rune(name)) > nameWidth {
+			nameWidth = len([]rune(name))
+		}
+	}
+
+	descWidth := helpWidth - nameWidth - 4
+	if descWidth < 20 {
+		descWidth = 20
+	}
+	indent := strings.Repeat(" ", nameWidth+2)
+
+	lines := []string{}
+	for i, info := range known {
+		name := names[i] + strings.Repeat(" ", nameWidth-len([]rune(names[i])))
+		desc := wrapWords(info.desc, descWidth)
+		if len(desc) == 0 {
+			lines = append(lines, strings.TrimRight(FormatHelpPrefix+" "+name, " "))
+			continue
+		}
+		lines = append(lines, FormatHelpPrefix+" "+name+"  "+desc[0])
+		for _, line := range desc[1:] {
+			lines = append(lines, FormatHelpPrefix+" "+indent+line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Bricht einen Text an Wortgrenzen in Zeilen mit maximal width Zeichen um.
+func wrapWords(text string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -1,17 +1,28 @@
 package cmdline
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 type Opts struct {
-	verbose bool
-	help    string
-	file    string
-	level   int
-	cmd     string
-	args    []string
+	verbose   bool
+	help      string
+	file      string
+	level     int
+	threshold float64
+	cmd       string
+	args      []string
 }
 
 func parse_cmdline(s string) (Opts, error) {
@@ -34,6 +45,8 @@ func parse_cmdline(s string) (Opts, error) {
 			opts.file = p.StrVal()
 		case p.IsIntOpt("level", "l", 0, 3):
 			opts.level = p.IntVal()
+		case p.IsFloatOpt("threshold", "t", 0, 1):
+			opts.threshold = p.FloatVal()
 		case p.IsArgN(0):
 			opts.cmd = p.Arg()
 		case p.IsArg() && p.ArgIdx() < 3:
@@ -44,6 +57,11 @@ func parse_cmdline(s string) (Opts, error) {
 	return opts, err
 }
 
+func parse_with(s string, fn func(p *Parser)) error {
+	ErrorFunc = ReturnError
+	return ParseArgs(strings.Fields(s), fn)
+}
+
 func TestLongOpts(t *testing.T) {
 	opts, err := parse_cmdline("/usr/bin/cmdline --verbose --file=file.txt --level=2 cmd arg0 arg1")
 	assertEqual(t, Program, "cmdline")
@@ -82,30 +100,1985 @@ func TestHelp(t *testing.T) {
 	assertEqual(t, len(opts.args), 0)
 }
 
-func TestOnlyArgs(t *testing.T) {
-	opts, err := parse_cmdline("cmdline -v -- cmd --file=file.txt")
+func TestHelpShortFlag(t *testing.T) {
+	defer func() {
+		HelpShortFlag = "h"
+	}()
+
+	opts, err := parse_cmdline("cmdline -h --verbose")
+	assertSuccess(t, err)
+	assertFalse(t, opts.verbose)
+	assertEqual(t, opts.help, Help)
+
+	host := ""
+	err = parse_with("cmdline -h localhost", func(p *Parser) {
+		if p.IsStrOpt("host", "h") {
+			host = p.StrVal()
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, host, "localhost")
+
+	HelpShortFlag = ""
+
+	_, err = parse_cmdline("cmdline -h")
+	assertError(t, err, "Unbekannte Option: --h")
+}
+
+func TestClusteredShortOpts(t *testing.T) {
+	opts, err := parse_cmdline("cmdline -vf file.txt cmd")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.file, "file.txt")
+	assertEqual(t, opts.cmd, "cmd")
+
+	opts, err = parse_cmdline("cmdline -vl 2")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.level, 2)
+
+	_, err = parse_cmdline("cmdline -vx")
+	assertError(t, err, "Unbekannte Option: --vx")
+
+	opts, err = parse_cmdline("cmdline -vf= next")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.file, "")
+	assertEqual(t, opts.cmd, "next")
+}
+
+func TestClusteredShortOptsDefaultBranch(t *testing.T) {
+	verbose, extra, args := false, false, []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsOpt("extra", "x"):
+			extra = true
+		case p.IsArg():
+			args = append(args, p.Arg())
+		default:
+			p.Errorf("Ungültig: %s", p.RawOpt())
+		}
+	}
+
+	err := parse_with("cmdline -vx +foo", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+	assertTrue(t, extra)
+	assertEqual(t, strings.Join(args, " "), "+foo")
+
+	err = parse_with("cmdline -vy", fn)
+	assertError(t, err, "Ungültig: -vy")
+}
+
+func TestSingleDashLongOpts(t *testing.T) {
+	defer func() {
+		SingleDashLongOptions = false
+	}()
+
+	opts, err := parse_cmdline("cmdline -verbose -vf file.txt")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.file, "file.txt")
+
+	SingleDashLongOptions = true
+
+	opts, err = parse_cmdline("cmdline -verbose -file file.txt -l 2")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.file, "file.txt")
+	assertEqual(t, opts.level, 2)
+
+	opts, err = parse_cmdline("cmdline -v")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+
+	_, err = parse_cmdline("cmdline -vf file.txt")
+	assertError(t, err, "Unbekannte Option: --vf")
+}
+
+func TestAttachedShortOptVal(t *testing.T) {
+	for _, cmdline := range []string{
+		"cmdline -ffile.txt",
+		"cmdline -f file.txt",
+		"cmdline -f=file.txt",
+		"cmdline --file=file.txt",
+	} {
+		opts, err := parse_cmdline(cmdline)
+		assertSuccess(t, err)
+		assertEqual(t, opts.file, "file.txt")
+	}
+
+	opts, err := parse_cmdline("cmdline -vl2")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.level, 2)
+
+	opts, err = parse_cmdline("cmdline -vf=a=b")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.file, "a=b")
+}
+
+func TestShortOptWithEquals(t *testing.T) {
+	opts, err := parse_cmdline("cmdline -l=2")
+	assertSuccess(t, err)
+	assertEqual(t, opts.level, 2)
+
+	opts, err = parse_cmdline("cmdline -vl=3")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.level, 3)
+
+	_, err = parse_cmdline("cmdline -l=")
+	assertError(t, err, "Ungültige Zahl:  (Option --level)")
+
+	_, err = parse_cmdline("cmdline -v=1")
+	assertError(t, err, "Option erlaubt kein Options-Argument: --verbose")
+}
+
+func TestRawOpt(t *testing.T) {
+	opt, rawOpt := "", ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("file", "f"):
+			opt, rawOpt = p.Opt(), p.RawOpt()
+		}
+	}
+
+	err := parse_with("cmdline -f file.txt", fn)
+	assertSuccess(t, err)
+	assertEqual(t, opt, "file")
+	assertEqual(t, rawOpt, "-f")
+
+	err = parse_with("cmdline --file=file.txt", fn)
+	assertSuccess(t, err)
+	assertEqual(t, opt, "file")
+	assertEqual(t, rawOpt, "--file")
+
+	err = parse_with("cmdline -vffile.txt", fn)
+	assertSuccess(t, err)
+	assertEqual(t, rawOpt, "-f")
+}
+
+func TestPlusOpt(t *testing.T) {
+	verbose := 0
+	args := []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = 1
+		case p.IsPlusOpt("verbose", "v"):
+			verbose = -1
+		case p.IsArg():
+			args = append(args, p.Arg())
+		}
+	}
+
+	err := parse_with("cmdline +v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, verbose, -1)
+
+	err = parse_with("cmdline -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, verbose, 1)
+
+	err = parse_with("cmdline +verbose +x", fn)
+	assertSuccess(t, err)
+	assertEqual(t, verbose, -1)
+	assertEqual(t, len(args), 1)
+	assertEqual(t, args[0], "+x")
+
+	err = parse_with("cmdline +v=1", fn)
+	assertError(t, err, "Option erlaubt kein Options-Argument: --verbose")
+}
+
+func TestShortNamePanic(t *testing.T) {
+	defer func() {
+		assertEqual(t, recover(), "short option muss ein Zeichen sein: ver")
+	}()
+
+	parse_with("cmdline --verbose", func(p *Parser) {
+		p.IsOpt("verbose", "ver")
+	})
+	t.Error("keine panic")
+}
+
+func TestCheckOptionNames(t *testing.T) {
+	defer func() {
+		CheckOptionNames = false
+		assertEqual(t, recover(), "kurze Option -f für --file und --force verwendet")
+	}()
+
+	fn := func(p *Parser) {
+		p.Flag("file", "f", "Datei anzeigen")
+		p.Flag("force", "f", "Überschreiben")
+
+		switch {
+		case p.IsStrOpt("file", "f"):
+		case p.IsOpt("force", "f"):
+		}
+	}
+
+	err := parse_with("cmdline --force", fn)
+	assertSuccess(t, err)
+
+	CheckOptionNames = true
+	parse_with("cmdline --force", fn)
+	t.Error("keine panic")
+}
+
+func TestFlag(t *testing.T) {
+	verbose := false
+	fn := func(p *Parser) {
+		switch {
+		case p.IsFlag("verbose", "v"):
+			verbose = true
+		}
+	}
+
+	err := parse_with("cmdline --verbose", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+
+	verbose = false
+	err = parse_with("cmdline -v", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+
+	err = parse_with("cmdline --verbose=1", fn)
+	assertError(t, err, "Option erlaubt kein Options-Argument: --verbose")
+}
+
+func TestCountOpt(t *testing.T) {
+	verbose := 0
+	fn := func(p *Parser) {
+		switch {
+		case p.IsCountOpt("verbose", "v"):
+			verbose = p.CountVal()
+		}
+	}
+
+	err := parse_with("cmdline -v --verbose -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, verbose, 3)
+
+	err = parse_with("cmdline -vvv", fn)
+	assertSuccess(t, err)
+	assertEqual(t, verbose, 3)
+
+	err = parse_with("cmdline --verbose=2", fn)
+	assertError(t, err, "Option erlaubt kein Options-Argument: --verbose")
+}
+
+func TestBoolOpt(t *testing.T) {
+	verbose := false
+	fn := func(p *Parser) {
+		switch {
+		case p.IsBoolOpt("verbose", "v"):
+			verbose = p.BoolVal()
+		}
+	}
+
+	err := parse_with("cmdline --verbose", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+
+	err = parse_with("cmdline --verbose --no-verbose", fn)
+	assertSuccess(t, err)
+	assertFalse(t, verbose)
+
+	err = parse_with("cmdline --verbose=false", fn)
+	assertSuccess(t, err)
+	assertFalse(t, verbose)
+
+	err = parse_with("cmdline -v", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+
+	err = parse_with("cmdline --no-verbose=true", fn)
+	assertError(t, err, "Option erlaubt kein Options-Argument: --no-verbose")
+
+	err = parse_with("cmdline -v=false", fn)
+	assertError(t, err, "Option erlaubt kein Options-Argument: --verbose")
+
+	err = parse_with("cmdline --verbose=1 --verbose=0", fn)
+	assertSuccess(t, err)
+	assertFalse(t, verbose)
+
+	err = parse_with("cmdline --verbose=0", func(p *Parser) {
+		p.IsOpt("verbose", "v")
+	})
+	assertError(t, err, "Option erlaubt kein Options-Argument: --verbose")
+
+	err = parse_with("cmdline --verbose=maybe", fn)
+	assertError(t, err, "Ungültiger Wahrheitswert: maybe (Option --verbose)")
+
+	for _, val := range []string{"true", "YES", "On", "1", "enabled"} {
+		verbose = false
+		err = parse_with("cmdline --verbose="+val, fn)
+		assertSuccess(t, err)
+		assertTrue(t, verbose)
+	}
+
+	for _, val := range []string{"FALSE", "no", "off", "0", "Disabled"} {
+		verbose = true
+		err = parse_with("cmdline --verbose="+val, fn)
+		assertSuccess(t, err)
+		assertFalse(t, verbose)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	file := "default.txt"
+	parser := NewParser(strings.Fields("cmdline --verbose --file=file.txt"))
+	err := parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("file", "f"):
+			file = p.StrVal()
+		case p.IsIntOpt("level", "l", 0, 3):
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, file, "file.txt")
+	assertTrue(t, parser.Changed("verbose"))
+	assertTrue(t, parser.Changed("file"))
+	assertFalse(t, parser.Changed("level"))
+}
+
+func TestProgramPath(t *testing.T) {
+	program, programPath := Program, ProgramPath
+	defer func() {
+		Program, ProgramPath = program, programPath
+	}()
+
+	Program = ""
+	err := ParseArgs([]string{"/usr/bin/cmdline"}, func(p *Parser) {})
+	assertSuccess(t, err)
+	assertEqual(t, ProgramPath, "/usr/bin/cmdline")
+	assertEqual(t, Program, "cmdline")
+
+	config := Config{ErrorFunc: ReturnError}
+	parser := config.NewParser([]string{"./bin/tool", "-x"})
+	err = parser.Parse(func(p *Parser) {})
+	assertError(t, err, "Unbekannte Option: --x")
+	assertEqual(t, parser.ProgramPath(), "./bin/tool")
+	assertEqual(t, parser.Program(), "tool")
+	assertEqual(t, config.ProgramPath, "")
+	assertEqual(t, config.Program, "")
+
+	parser = config.NewParser([]string{"/bin/two"})
+	assertEqual(t, parser.ProgramPath(), "/bin/two")
+	assertEqual(t, parser.Program(), "two")
+}
+
+func TestEmptyArgs(t *testing.T) {
+	program := Program
+	defer func() {
+		Program = program
+	}()
+
+	Program = ""
+	called := false
+	err := ParseArgs([]string{}, func(p *Parser) {
+		called = true
+	})
+	assertSuccess(t, err)
+	assertFalse(t, called)
+	assertEqual(t, Program, filepath.Base(os.Args[0]))
+
+	Program = "mytool"
+	err = ParseArgs(nil, func(p *Parser) {})
+	assertSuccess(t, err)
+	assertEqual(t, Program, "mytool")
+}
+
+func TestProvidedOptions(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	parser := NewParser(strings.Fields("cmdline --verbose cmd --file=x -l 2"))
+	err := parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("file", "f"):
+		case p.IsIntOpt("level", "l", 0, 3):
+		case p.IsArg():
+			p.Arg()
+		}
+	})
+	assertSuccess(t, err)
+
+	provided := parser.ProvidedOptions()
+	assertEqual(t, len(provided), 3)
+	assertEqual(t, provided[0], ProvidedOption{"verbose", ""})
+	assertEqual(t, provided[1], ProvidedOption{"file", "x"})
+	assertEqual(t, provided[2], ProvidedOption{"level", "2"})
+
+	parser = NewParser(strings.Fields("cmdline --color --no-color --color=off"))
+	err = parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsBoolOpt("color", ""):
+		}
+	})
+	assertSuccess(t, err)
+
+	provided = parser.ProvidedOptions()
+	assertEqual(t, len(provided), 3)
+	assertEqual(t, provided[0], ProvidedOption{"color", "true"})
+	assertEqual(t, provided[1], ProvidedOption{"color", "false"})
+	assertEqual(t, provided[2], ProvidedOption{"color", "false"})
+}
+
+func TestConfig(t *testing.T) {
+	var errMsg string
+	program := Program
+	config := &Config{
+		Help: "Verwendung: other",
+		ErrorFunc: func(format string, args ...any) {
+			errMsg = fmt.Sprintf(format, args...)
+		},
+	}
+
+	verbose := false
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		}
+	}
+
+	parser := config.NewParser(strings.Fields("/usr/bin/other -v"))
+	err := parser.Parse(fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+	assertEqual(t, parser.Program(), "other")
+	assertEqual(t, config.Program, "")
+	assertEqual(t, Program, program)
+
+	err = config.ParseArgs(strings.Fields("other --unknown"), fn)
+	assertError(t, err, "Unbekannte Option: --unknown")
+	assertEqual(t, errMsg, "Unbekannte Option: --unknown")
+}
+
+func TestConfigConcurrent(t *testing.T) {
+	config := &Config{ErrorFunc: ReturnError}
+	done := make(chan error)
+	for _, program := range []string{"/bin/one", "/bin/two"} {
+		go func(program string) {
+			parser := config.NewParser([]string{program, "-v"})
+			err := parser.Parse(func(p *Parser) {
+				p.IsOpt("verbose", "v")
+			})
+			if err == nil && parser.Program() != path.Base(program) {
+				err = fmt.Errorf("falsches Programm: %s", parser.Program())
+			}
+			done <- err
+		}(program)
+	}
+	assertSuccess(t, <-done)
+	assertSuccess(t, <-done)
+}
+
+func TestOptValWithEquals(t *testing.T) {
+	expr := ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("expr", "e"):
+			expr = p.StrVal()
+		}
+	}
+
+	err := parse_with("cmdline --expr==b", fn)
+	assertSuccess(t, err)
+	assertEqual(t, expr, "=b")
+
+	err = parse_with("cmdline --expr ==b", fn)
+	assertSuccess(t, err)
+	assertEqual(t, expr, "==b")
+
+	err = parse_with("cmdline -e=a=b", fn)
+	assertSuccess(t, err)
+	assertEqual(t, expr, "a=b")
+
+	err = parse_with("cmdline -ve==x", fn)
+	assertSuccess(t, err)
+	assertEqual(t, expr, "=x")
+}
+
+func TestDoubleDashOptVal(t *testing.T) {
+	sep := ""
+	verbose := false
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsStrOpt("sep", "s"):
+			sep = p.StrVal()
+		}
+	}
+
+	err := parse_with("cmdline --sep -- -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, sep, "--")
+	assertTrue(t, verbose)
+}
+
+func TestStrOptAlias(t *testing.T) {
+	output := ""
+	var parser *Parser
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrOptAlias([]string{"output", "out", "o"}):
+			output = p.StrVal()
+		}
+	}
+
+	ErrorFunc = ReturnError
+	for _, arg := range []string{"--output=a.txt", "--out=a.txt", "-o a.txt"} {
+		output = ""
+		parser = NewParser(strings.Fields("cmdline " + arg))
+		assertSuccess(t, parser.Parse(fn))
+		assertEqual(t, output, "a.txt")
+		assertTrue(t, parser.Changed("output"))
+		assertEqual(t, parser.Opt(), "output")
+	}
+
+	err := parse_with("cmdline --out", fn)
+	assertError(t, err, "Option erwartet ein Options-Argument: --output")
+}
+
+func TestStrOptAliasCheckOptionNames(t *testing.T) {
+	defer func() {
+		CheckOptionNames = false
+		assertEqual(t, recover(), "Option --out mehrfach verwendet")
+	}()
+
+	CheckOptionNames = true
+	parse_with("cmdline", func(p *Parser) {
+		switch {
+		case p.IsOpt("out", ""):
+		case p.IsStrOptAlias([]string{"output", "out"}):
+		}
+	})
+	t.Error("keine panic")
+}
+
+func TestStrOptAliasRegistered(t *testing.T) {
+	defer func() {
+		AbbreviatedOptions = false
+	}()
+
+	output, outline, verbose := "", false, false
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsOpt("outline", ""):
+			outline = true
+		case p.IsStrOptAlias([]string{"output", "out", "o"}):
+			output = p.StrVal()
+		}
+	}
+
+	AbbreviatedOptions = true
+	err := parse_with("cmdline --out a.txt", fn)
+	assertSuccess(t, err)
+	assertEqual(t, output, "a.txt")
+	assertFalse(t, outline)
+
+	err = parse_with("cmdline --ou a.txt", fn)
+	assertError(t, err, "Mehrdeutige Option: --ou")
+
+	output = ""
+	err = parse_with("cmdline -vo a.txt", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+	assertEqual(t, output, "a.txt")
+
+	output = ""
+	err = parse_with("cmdline -ob.txt", fn)
+	assertSuccess(t, err)
+	assertEqual(t, output, "b.txt")
+
+	parser := NewParser(strings.Fields("cmdline"))
+	parser.Parse(fn)
+	assertTrue(t, strings.Contains(parser.BuildHelp(), "-o, --output, --out=OUTPUT"))
+}
+
+func TestStrOptGreedy(t *testing.T) {
+	prefix := ""
+	verbose := false
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsStrOptGreedy("prefix", "p"):
+			prefix = p.StrVal()
+		}
+	}
+
+	err := parse_with("cmdline --prefix=-x", fn)
+	assertSuccess(t, err)
+	assertEqual(t, prefix, "-x")
+
+	err = parse_with("cmdline --prefix -x", fn)
+	assertSuccess(t, err)
+	assertEqual(t, prefix, "-x")
+
+	err = parse_with("cmdline -p -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, prefix, "-v")
+	assertFalse(t, verbose)
+
+	err = parse_with("cmdline --prefix", fn)
+	assertError(t, err, "Option erwartet ein Options-Argument: --prefix")
+}
+
+func TestOptionalStrOpt(t *testing.T) {
+	color := ""
+	args := []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOptionalStrOpt("color", "c", "auto"):
+			color = p.StrVal()
+		case p.IsArg():
+			args = append(args, p.Arg())
+		}
+	}
+
+	err := parse_with("cmdline --color", fn)
+	assertSuccess(t, err)
+	assertEqual(t, color, "auto")
+
+	err = parse_with("cmdline --color=never", fn)
+	assertSuccess(t, err)
+	assertEqual(t, color, "never")
+
+	err = parse_with("cmdline --color cmd", fn)
+	assertSuccess(t, err)
+	assertEqual(t, color, "auto")
+	assertEqual(t, len(args), 1)
+	assertEqual(t, args[0], "cmd")
+}
+
+func TestPairOpt(t *testing.T) {
+	from, to := "", ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsPairOpt("range", "r"):
+			from, to = p.StrVal(), p.SecondVal()
+		}
+	}
+
+	err := parse_with("cmdline --range 10 20 -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, from, "10")
+	assertEqual(t, to, "20")
+
+	err = parse_with("cmdline -r=1,2", fn)
+	assertSuccess(t, err)
+	assertEqual(t, from, "1")
+	assertEqual(t, to, "2")
+
+	err = parse_with("cmdline --range=5 6", fn)
+	assertSuccess(t, err)
+	assertEqual(t, from, "5")
+	assertEqual(t, to, "6")
+
+	err = parse_with("cmdline --range 10", fn)
+	assertError(t, err, "Option erwartet ein Options-Argument: --range")
+
+	err = parse_with("cmdline --range 10 -v", fn)
+	assertError(t, err, "Option erwartet ein Options-Argument: --range")
+}
+
+func TestStrOptFunc(t *testing.T) {
+	dir := ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrOptFunc("dir", "d", func(s string) error {
+			if !strings.HasPrefix(s, "/") {
+				return fmt.Errorf("Pfad muß absolut sein: %s", s)
+			}
+			return nil
+		}):
+			dir = p.StrVal()
+		}
+	}
+
+	err := parse_with("cmdline --dir=/tmp", fn)
+	assertSuccess(t, err)
+	assertEqual(t, dir, "/tmp")
+
+	err = parse_with("cmdline -d tmp", fn)
+	assertError(t, err, "Pfad muß absolut sein: tmp (Option --dir)")
+}
+
+func TestStrSliceOpt(t *testing.T) {
+	var includes []string
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrSliceOpt("include", "I"):
+			includes = p.StrSliceVal()
+		}
+	}
+
+	err := parse_with("cmdline --include=a -I b --include c", fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(includes), 3)
+	assertEqual(t, includes[0], "a")
+	assertEqual(t, includes[1], "b")
+	assertEqual(t, includes[2], "c")
+}
+
+func TestMapOpt(t *testing.T) {
+	var defines map[string]string
+	fn := func(p *Parser) {
+		switch {
+		case p.IsMapOpt("define", "D"):
+			defines = p.MapVal()
+		}
+	}
+
+	err := parse_with("cmdline -D FOO=bar --define=BAZ=qux=1 -D EMPTY -D FOO=new", fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(defines), 3)
+	assertEqual(t, defines["FOO"], "new")
+	assertEqual(t, defines["BAZ"], "qux=1")
+	assertEqual(t, defines["EMPTY"], "")
+}
+
+func TestCsvOpt(t *testing.T) {
+	var tags []string
+	fn := func(p *Parser) {
+		switch {
+		case p.IsCsvOpt("tags", "t"):
+			tags = p.CsvVal()
+		}
+	}
+
+	err := parse_with("cmdline --tags=a,,b,c,", fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(tags), 3)
+	assertEqual(t, tags[0], "a")
+	assertEqual(t, tags[1], "b")
+	assertEqual(t, tags[2], "c")
+
+	err = parse_with("cmdline -t ,", fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(tags), 0)
+
+	tags = nil
+	err = parse_with("cmdline --tags=", fn)
+	assertSuccess(t, err)
+	assertTrue(t, tags != nil)
+	assertEqual(t, len(tags), 0)
+
+	assertEqual(t, len(splitCsv("")), 0)
+	assertEqual(t, len(splitCsv(" a , b ")), 2)
+
+	err = parse_with(`cmdline --tags=a\,b,c`, fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(tags), 2)
+	assertEqual(t, tags[0], "a,b")
+	assertEqual(t, tags[1], "c")
+
+	err = parse_with(`cmdline --tags=a,b\,`, fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(tags), 2)
+	assertEqual(t, tags[1], "b,")
+
+	assertEqual(t, strings.Join(splitCsv(`a\\,b\x`), "|"), `a\|b\x`)
+}
+
+func TestHelpRequested(t *testing.T) {
+	called := false
+	config := &Config{Help: "Verwendung: cmdline", HelpFunc: func(help string) {}}
+	fn := func(p *Parser) {
+		called = true
+		p.IsOpt("verbose", "v")
+	}
+
+	parser := config.NewParser(strings.Fields("cmdline --help -v"))
+	assertSuccess(t, parser.Parse(fn))
+	assertTrue(t, parser.HelpRequested())
+	assertFalse(t, called)
+	assertEqual(t, len(parser.Rest()), 1)
+
+	parser = config.NewParser(strings.Fields("cmdline -v"))
+	assertSuccess(t, parser.Parse(fn))
+	assertFalse(t, parser.HelpRequested())
+
+	// ohne Help bzw. für -h wird die Callback-Funktion nur im Scan-Modus aufgerufen
+	calls, verbose, help := 0, false, ""
+	fn = func(p *Parser) {
+		calls++
+		if p.IsOpt("verbose", "v") {
+			verbose = true
+		}
+	}
+	config = &Config{HelpShortFlag: "h", HelpFunc: func(h string) { help = h }}
+	for _, args := range []string{"cmdline --help -v", "cmdline -h -v"} {
+		calls, verbose, help = 0, false, ""
+		parser = config.NewParser(strings.Fields(args))
+		assertSuccess(t, parser.Parse(fn))
+		assertTrue(t, parser.HelpRequested())
+		assertEqual(t, calls, 1)
+		assertFalse(t, verbose)
+		assertTrue(t, strings.Contains(help, "--verbose"))
+	}
+
+	config.HelpAsError = true
+	calls = 0
+	parser = config.NewParser(strings.Fields("cmdline -h -v"))
+	assertTrue(t, errors.Is(parser.Parse(fn), ErrHelpRequested))
+	assertEqual(t, calls, 1)
+	assertFalse(t, verbose)
+}
+
+func TestDisableAutoHelp(t *testing.T) {
+	defer func() {
+		DisableAutoHelp = false
+	}()
+	DisableAutoHelp = true
+
+	help, verbose := false, false
+	err := parse_with("cmdline --help -v", func(p *Parser) {
+		switch {
+		case p.IsOpt("help", "h"):
+			help = true
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		}
+	})
+	assertSuccess(t, err)
+	assertTrue(t, help)
+	assertTrue(t, verbose)
+}
+
+func TestHelpAsError(t *testing.T) {
+	help := Help
+	defer func() {
+		HelpAsError = false
+		Help = help
+	}()
+	HelpAsError = true
+
+	Help = ""
+	called := false
+	err := parse_with("cmdline --help -v", func(p *Parser) {
+		called = true
+	})
+	assertTrue(t, errors.Is(err, ErrHelpRequested))
+	assertFalse(t, called)
+
+	_, err = parse_cmdline("cmdline -v -h")
+	assertTrue(t, errors.Is(err, ErrHelpRequested))
+}
+
+func TestVersion(t *testing.T) {
+	defer func() {
+		Version = ""
+		VersionFunc = PrintVersion
+	}()
+
+	_, err := parse_cmdline("cmdline --version")
+	assertError(t, err, "Unbekannte Option: --version")
+
+	version := ""
+	Version = "1.2.3"
+	VersionFunc = func(v string) {
+		version = v
+	}
+
+	opts, err := parse_cmdline("cmdline --version --verbose")
+	assertSuccess(t, err)
+	assertEqual(t, version, "1.2.3")
+	assertFalse(t, opts.verbose)
+}
+
+func TestRequire(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("file", "f"):
+		}
+	}
+
+	parser := NewParser(strings.Fields("cmdline --file=file.txt"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.Require("file"))
+
+	parser = NewParser(strings.Fields("cmdline --verbose"))
+	assertSuccess(t, parser.Parse(fn))
+	err := parser.Require("verbose", "file")
+	assertError(t, err, "Fehlende Option: --file")
+
+	var parseErr *ParseError
+	assertTrue(t, errors.As(err, &parseErr))
+	assertEqual(t, parseErr.Kind, MissingOption)
+	assertEqual(t, parseErr.Option, "file")
+}
+
+func TestAbbreviatedOpts(t *testing.T) {
+	defer func() {
+		AbbreviatedOptions = false
+	}()
+
+	verbose := false
+	file := ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsOpt("force", ""):
+		case p.IsStrOpt("file", ""):
+			file = p.StrVal()
+		}
+	}
+
+	err := parse_with("cmdline --verb", fn)
+	assertError(t, err, "Unbekannte Option: --verb")
+
+	AbbreviatedOptions = true
+
+	err = parse_with("cmdline --verb --fil=file.txt", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+	assertEqual(t, file, "file.txt")
+
+	err = parse_with("cmdline --f", fn)
+	assertError(t, err, "Mehrdeutige Option: --f")
+
+	err = parse_with("cmdline -v --for", fn)
+	assertSuccess(t, err)
+}
+
+func TestCaseInsensitiveOpts(t *testing.T) {
+	defer func() {
+		CaseInsensitiveOptions = false
+	}()
+
+	opts, err := parse_cmdline("cmdline --VERBOSE")
+	assertError(t, err, "Unbekannte Option: --VERBOSE")
+
+	CaseInsensitiveOptions = true
+
+	opts, err = parse_cmdline("cmdline --VERBOSE --File=file.txt -L 2")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.file, "file.txt")
+	assertEqual(t, opts.level, 2)
+
+	_, err = parse_cmdline("cmdline --LEVEL=4")
+	assertError(t, err, "Zahl muß <= 3 sein: 4 (Option --level)")
+}
+
+func TestDidYouMean(t *testing.T) {
+	_, err := parse_cmdline("cmdline --verbsoe")
+	assertError(t, err, "Unbekannte Option: --verbsoe Meinten Sie --verbose?")
+
+	_, err = parse_cmdline("cmdline --lvel=1")
+	assertError(t, err, "Unbekannte Option: --lvel Meinten Sie --level?")
+
+	_, err = parse_cmdline("cmdline --completely-wrong")
+	assertError(t, err, "Unbekannte Option: --completely-wrong")
+}
+
+func TestFromEnv(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	port := 80
+	fn := func(p *Parser) {
+		switch {
+		case p.IsIntOpt("port", "p", 1, 65535):
+			port = p.IntVal()
+		}
+	}
+
+	t.Setenv("MYTOOL_PORT", "8080")
+
+	parser := NewParser(strings.Fields("cmdline"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.FromEnv("port", "MYTOOL_PORT"))
+	assertEqual(t, port, 8080)
+
+	parser = NewParser(strings.Fields("cmdline --port=443"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.FromEnv("port", "MYTOOL_PORT"))
+	assertEqual(t, port, 443)
+
+	parser = NewParser(strings.Fields("cmdline"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.FromEnv("port", "MYTOOL_UNSET_PORT"))
+
+	t.Setenv("MYTOOL_PORT", "99999")
+	parser = NewParser(strings.Fields("cmdline"))
+	assertSuccess(t, parser.Parse(fn))
+	err := parser.FromEnv("port", "MYTOOL_PORT")
+	assertError(t, err, "Zahl muß <= 65535 sein: 99999 (Option --port)")
+
+	t.Setenv("MYTOOL_PORT", "8080")
+	parser = NewParser(strings.Fields("cmdline"))
+	assertSuccess(t, parser.Parse(fn))
+	err = parser.Require("file")
+	assertError(t, err, "Fehlende Option: --file")
+	assertSuccess(t, parser.FromEnv("port", "MYTOOL_PORT"))
+	assertEqual(t, port, 8080)
+
+	port, msg := 80, ""
+	t.Setenv("MYTOOL_MESSAGE", "hallo")
+	parser = NewParser(strings.Fields("cmdline commit"))
+	err = parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsIntOpt("port", "p", 1, 65535):
+			port = p.IntVal()
+		case p.IsCmd("commit"):
+			p.ParseRest(func(p *Parser) {
+				switch {
+				case p.IsStrOpt("message", "m"):
+					msg = p.StrVal()
+				}
+			})
+		}
+	})
+	assertSuccess(t, err)
+	assertSuccess(t, parser.FromEnv("port", "MYTOOL_PORT"))
+	assertSuccess(t, parser.FromEnv("message", "MYTOOL_MESSAGE"))
+	assertEqual(t, port, 8080)
+	assertEqual(t, msg, "hallo")
+
+	t.Setenv("MYTOOL_OTHER", "x")
+	err = parser.FromEnv("other", "MYTOOL_OTHER")
+	assertError(t, err, "Unbekannte Option: --other")
+}
+
+func TestLoadDefaults(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	port, host := 80, ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsIntOpt("port", "p", 1, 65535):
+			port = p.IntVal()
+		case p.IsStrOpt("host", "h"):
+			host = p.StrVal()
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "app.ini")
+	ini := "# Defaults\n[server]\nport = 8080\nhost = \"local host\"\n"
+	assertSuccess(t, os.WriteFile(file, []byte(ini), 0644))
+
+	defaults, err := LoadDefaults(file)
+	assertSuccess(t, err)
+	assertEqual(t, len(defaults), 2)
+
+	parser := NewParser(strings.Fields("cmdline"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.ApplyDefaults(defaults))
+	assertEqual(t, port, 8080)
+	assertEqual(t, host, "local host")
+
+	parser = NewParser(strings.Fields("cmdline --port=443"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.ApplyDefaults(defaults))
+	assertEqual(t, port, 443)
+
+	parser = NewParser(strings.Fields("cmdline"))
+	assertSuccess(t, parser.Parse(fn))
+	err = parser.ApplyDefaults(map[string]string{"prot": "1"})
+	assertError(t, err, "Unbekannte Option: --prot Meinten Sie --port?")
+
+	assertSuccess(t, os.WriteFile(file, []byte("port 8080\n"), 0644))
+	_, err = LoadDefaults(file)
+	assertError(t, err, file+", Zeile 1: Ungültige Zeile: port 8080")
+
+	_, err = LoadDefaults(filepath.Join(t.TempDir(), "missing.ini"))
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestArgs(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	parser := NewParser(strings.Fields("cmdline cmd -f file.txt a --verbose b"))
+	err := parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("file", "f"):
+		case p.IsArg():
+			p.Arg()
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, strings.Join(parser.Args(), " "), "cmd a b")
+}
+
+func TestArgRange(t *testing.T) {
+	cmd, inputs, rest := "", []string{}, []string{}
+	err := parse_with("cmdline cmd a b c", func(p *Parser) {
+		switch {
+		case p.IsArgN(0):
+			cmd = p.Arg()
+		case p.IsArgRange(1, 3):
+			inputs = append(inputs, p.Arg())
+		case p.IsArg():
+			rest = append(rest, p.Arg())
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, cmd, "cmd")
+	assertEqual(t, strings.Join(inputs, " "), "a b")
+	assertEqual(t, strings.Join(rest, " "), "c")
+}
+
+func TestStdinArg(t *testing.T) {
+	stdin := false
+	args := []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStdinArg():
+			stdin = true
+			p.Arg()
+		case p.IsArg():
+			args = append(args, p.Arg())
+		}
+	}
+
+	err := parse_with("cmdline a -", fn)
+	assertSuccess(t, err)
+	assertTrue(t, stdin)
+	assertEqual(t, strings.Join(args, " "), "a")
+
+	stdin = false
+	args = nil
+	err = parse_with("cmdline -- a", fn)
+	assertSuccess(t, err)
+	assertFalse(t, stdin)
+	assertEqual(t, strings.Join(args, " "), "a")
+}
+
+func TestArgsWithParseRest(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	parser := NewParser(strings.Fields("cmdline -v cmd a -f file.txt b"))
+	err := parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsCmd("cmd"):
+			p.ParseRest(func(p *Parser) {
+				switch {
+				case p.IsStrOpt("file", "f"):
+				case p.IsArg():
+					p.Arg()
+				}
+			})
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, len(parser.Args()), 3)
+	assertEqual(t, parser.ArgIdx(), 2)
+}
+
+func TestExpectArgs(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	parse := func(s string) *Parser {
+		parser := NewParser(strings.Fields(s))
+		err := parser.Parse(func(p *Parser) {
+			switch {
+			case p.IsOpt("verbose", "v"):
+			case p.IsArg():
+				p.Arg()
+			}
+		})
+		assertSuccess(t, err)
+		return parser
+	}
+
+	assertSuccess(t, parse("cmdline a b").ExpectArgs(2, 3))
+	assertSuccess(t, parse("cmdline a b c").ExpectArgs(2, 3))
+	assertSuccess(t, parse("cmdline a b c d e").ExpectArgs(2, -1))
+
+	err := parse("cmdline -v a").ExpectArgs(2, 3)
+	assertError(t, err, "Zu wenige Argumente (mindestens 2)")
+
+	err = parse("cmdline a b c d").ExpectArgs(2, 3)
+	assertError(t, err, "Zu viele Argumente (höchstens 3)")
+}
+
+func TestNamedArgs(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	source, dest := "", ""
+	parse := func(s string) *Parser {
+		parser := NewParser(strings.Fields(s))
+		err := parser.Parse(func(p *Parser) {
+			switch {
+			case p.IsNamedArg(0, "SOURCE"):
+				source = p.Arg()
+			case p.IsNamedArg(1, "DEST"):
+				dest = p.Arg()
+			case p.IsOpt("verbose", "v"):
+			default:
+				p.Errorf("Ungültig: %s", p.RawOpt())
+			}
+		})
+		assertSuccess(t, err)
+		return parser
+	}
+
+	assertSuccess(t, parse("cmdline a b").ExpectArgs(2, 2))
+	assertEqual(t, source, "a")
+	assertEqual(t, dest, "b")
+
+	err := parse("cmdline -v").ExpectArgs(2, 2)
+	assertError(t, err, "Fehlendes Argument: SOURCE")
+
+	err = parse("cmdline a -v").ExpectArgs(2, 2)
+	assertError(t, err, "Fehlendes Argument: DEST")
+
+	// ohne Auswertung von IsNamedArg ist der Name nicht bekannt
+	err = parse("cmdline").ExpectArgs(2, 2)
+	assertError(t, err, "Zu wenige Argumente (mindestens 2)")
+
+	// nach ParseRest zählen nur die Argumente des Unterkommandos
+	parser := NewParser(strings.Fields("cmdline copy x -v"))
+	err = parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsCmd("copy"):
+			p.ParseRest(func(p *Parser) {
+				switch {
+				case p.IsNamedArg(0, "SOURCE"):
+					p.Arg()
+				case p.IsNamedArg(1, "DEST"):
+					p.Arg()
+				case p.IsOpt("verbose", "v"):
+				}
+			})
+		}
+	})
+	assertSuccess(t, err)
+	err = parser.ExpectArgs(2, 2)
+	assertError(t, err, "Fehlendes Argument: DEST")
+}
+
+func TestDebugJSON(t *testing.T) {
+	debug := ""
+	err := parse_with("cmdline --level=2 a", func(p *Parser) {
+		switch {
+		case p.IsIntOpt("level", "l", 0, 3):
+			debug = p.DebugJSON()
+		case p.IsArg():
+			p.Arg()
+		}
+	})
+	assertSuccess(t, err)
+	assertTrue(t, json.Valid([]byte(debug)))
+
+	var state map[string]any
+	assertSuccess(t, json.Unmarshal([]byte(debug), &state))
+	assertEqual(t, state["opt"], "level")
+	assertEqual(t, state["intVal"], 2.0)
+	assertEqual(t, fmt.Sprint(state["rest"]), "[a]")
+}
+
+func TestGrabRest(t *testing.T) {
+	verbose := false
+	rest := []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsCmd("run"):
+			rest = p.GrabRest()
+		}
+	}
+
+	err := parse_with("cmdline -v run -- prog -v a b", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+	assertEqual(t, strings.Join(rest, " "), "prog -v a b")
+
+	err = parse_with("cmdline run prog --verbose", fn)
+	assertSuccess(t, err)
+	assertEqual(t, strings.Join(rest, " "), "prog --verbose")
+
+	err = parse_with("cmdline run", fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(rest), 0)
+}
+
+func TestHasMore(t *testing.T) {
+	hasMore := []bool{}
+	err := parse_with("cmdline -v a b", func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			hasMore = append(hasMore, p.HasMore())
+		case p.IsArg():
+			hasMore = append(hasMore, p.HasMore())
+			p.Arg()
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, len(hasMore), 3)
+	assertTrue(t, hasMore[0])
+	assertTrue(t, hasMore[1])
+	assertFalse(t, hasMore[2])
+}
+
+func TestNextToken(t *testing.T) {
+	from, to := "", ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.Opt() == "range":
+			var ok1, ok2 bool
+			from, ok1 = p.NextToken()
+			to, ok2 = p.NextToken()
+			if !ok1 || !ok2 {
+				p.Errorf("Option --range benötigt zwei Werte")
+			}
+		}
+	}
+
+	err := parse_with("cmdline --range 10 20 -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, from, "10")
+	assertEqual(t, to, "20")
+
+	err = parse_with("cmdline --range 10", fn)
+	assertError(t, err, "Option --range benötigt zwei Werte")
+}
+
+func TestParseLine(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	file := ""
+	args := []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrOpt("file", "f"):
+			file = p.StrVal()
+		case p.IsArg():
+			args = append(args, p.Arg())
+		}
+	}
+
+	err := ParseLine(`cmd --file="a b.txt"`, fn)
+	assertSuccess(t, err)
+	assertEqual(t, file, "a b.txt")
+
+	err = ParseLine(`cmd -f 'x "y"' a\ b "c\"d" e\\f`, fn)
+	assertSuccess(t, err)
+	assertEqual(t, file, `x "y"`)
+	assertEqual(t, strings.Join(args, "|"), `a b|c"d|e\f`)
+
+	words, _ := splitLine(`a "" b`)
+	assertEqual(t, len(words), 3)
+
+	err = ParseLine(`cmd --file="a b.txt`, fn)
+	assertError(t, err, `Fehlendes Anführungszeichen: "`)
+
+	args = nil
+	err = ParseLine(`cmd -- "a b" -f 'c d'`, fn)
+	assertSuccess(t, err)
+	assertEqual(t, strings.Join(args, "|"), "a b|-f|c d")
+}
+
+func TestReset(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	verbose, count := false, 0
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsCountOpt("count", "c"):
+			count = p.CountVal()
+		case p.IsArg():
+			p.Arg()
+		}
+	}
+
+	parser := NewParser(strings.Fields("cmdline -v -c -c a b"))
+	assertSuccess(t, parser.Parse(fn))
+	assertEqual(t, count, 2)
+	assertEqual(t, len(parser.Args()), 2)
+
+	verbose = false
+	parser.Reset(strings.Fields("-c x"))
+	assertSuccess(t, parser.Parse(fn))
+	assertFalse(t, verbose)
+	assertFalse(t, parser.Changed("verbose"))
+	assertEqual(t, count, 1)
+	assertEqual(t, strings.Join(parser.Args(), " "), "x")
+
+	parser.Reset(strings.Fields("--unknown"))
+	assertError(t, parser.Parse(fn), "Unbekannte Option: --unknown")
+
+	parser.Reset(nil)
+	assertSuccess(t, parser.Parse(fn))
+}
+
+func TestStop(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	cmd := ""
+	parser := NewParser(strings.Fields("cmdline -v run cmd --file=file.txt arg"))
+	err := parser.Parse(func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsArgN(0):
+			cmd = p.Arg()
+			p.Stop()
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, cmd, "run")
+	assertEqual(t, strings.Join(parser.Rest(), " "), "cmd --file=file.txt arg")
+}
+
+func TestSubCommands(t *testing.T) {
+	verbose := false
+	cmd := ""
+	msg := ""
+	files := []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsCmd("push"):
+			cmd = "push"
+		case p.IsCmd("commit"):
+			cmd = "commit"
+			p.ParseRest(func(p *Parser) {
+				switch {
+				case p.IsStrOpt("message", "m"):
+					msg = p.StrVal()
+				case p.IsArgN(0) || p.IsArgN(1):
+					files = append(files, p.Arg())
+				}
+			})
+		}
+	}
+
+	err := parse_with("mytool -v commit -m msg a.txt b.txt", fn)
+	assertSuccess(t, err)
+	assertTrue(t, verbose)
+	assertEqual(t, cmd, "commit")
+	assertEqual(t, msg, "msg")
+	assertEqual(t, strings.Join(files, " "), "a.txt b.txt")
+
+	err = parse_with("mytool commit a b c", fn)
+	assertError(t, err, "Zu viele Argumente!")
+
+	err = parse_with("mytool push -m msg", fn)
+	assertError(t, err, "Unbekannte Option: --m")
+}
+
+func TestResetArgIdx(t *testing.T) {
+	cmd, first := "", ""
+	err := parse_with("mytool -v commit a.txt", func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsCmd("commit"):
+			cmd = "commit"
+			p.ResetArgIdx()
+		case p.IsArgN(0):
+			first = p.Arg()
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, cmd, "commit")
+	assertEqual(t, first, "a.txt")
+
+	err = parse_with("mytool commit a.txt", func(p *Parser) {
+		switch {
+		case p.IsCmd("commit"):
+			p.ParseRest(func(p *Parser) {
+				if p.IsArgN(0) {
+					first = p.Arg()
+				}
+			})
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, first, "a.txt")
+}
+
+func TestMutuallyExclusive(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("json", ""):
+		case p.IsOpt("yaml", ""):
+		case p.IsOpt("quiet", "q"):
+		case p.IsOpt("verbose", "v"):
+		}
+	}
+
+	parser := NewParser(strings.Fields("cmdline --json -v"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.MutuallyExclusive("json", "yaml"))
+	assertSuccess(t, parser.MutuallyExclusive("quiet", "verbose"))
+
+	parser = NewParser(strings.Fields("cmdline --yaml -q --json"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.MutuallyExclusive("quiet", "verbose"))
+	err := parser.MutuallyExclusive("json", "yaml")
+	assertError(t, err, "Optionen --json und --yaml schließen sich gegenseitig aus")
+}
+
+func TestRequires(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrOpt("cert", ""):
+		case p.IsStrOpt("key", ""):
+		}
+	}
+
+	parser := NewParser(strings.Fields("cmdline"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.Requires("cert", "key"))
+
+	parser = NewParser(strings.Fields("cmdline --cert=a.pem --key=a.key"))
+	assertSuccess(t, parser.Parse(fn))
+	assertSuccess(t, parser.Requires("cert", "key"))
+
+	parser = NewParser(strings.Fields("cmdline --cert=a.pem"))
+	assertSuccess(t, parser.Parse(fn))
+	err := parser.Requires("cert", "key")
+	assertError(t, err, "Option --cert benötigt --key")
+}
+
+func TestOnlyArgs(t *testing.T) {
+	opts, err := parse_cmdline("cmdline -v -- cmd --file=file.txt")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.file, "")
+	assertEqual(t, opts.cmd, "cmd")
+	assertEqual(t, opts.level, 0)
+	assertEqual(t, len(opts.args), 1)
+	assertEqual(t, opts.args[0], "--file=file.txt")
+}
+
+func TestDoubleDash(t *testing.T) {
+	opts, err := parse_cmdline("cmdline cmd a --")
+	assertSuccess(t, err)
+	assertEqual(t, opts.cmd, "cmd")
+	assertEqual(t, len(opts.args), 1)
+	assertEqual(t, opts.args[0], "a")
+
+	opts, err = parse_cmdline("cmdline cmd -- a")
+	assertSuccess(t, err)
+	assertEqual(t, opts.cmd, "cmd")
+	assertEqual(t, len(opts.args), 1)
+	assertEqual(t, opts.args[0], "a")
+
+	opts, err = parse_cmdline("cmdline -- -v --file=file.txt")
+	assertSuccess(t, err)
+	assertFalse(t, opts.verbose)
+	assertEqual(t, opts.cmd, "-v")
+	assertEqual(t, len(opts.args), 1)
+	assertEqual(t, opts.args[0], "--file=file.txt")
+}
+
+func TestKeepDoubleDash(t *testing.T) {
+	defer func() {
+		KeepDoubleDash = false
+	}()
+	KeepDoubleDash = true
+
+	args := []string{}
+	err := parse_with("wrap -- a -b", func(p *Parser) {
+		if p.IsArg() {
+			args = append(args, p.Arg())
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, strings.Join(args, " "), "-- a -b")
+}
+
+func TestRepeatedDoubleDash(t *testing.T) {
+	args := []string{}
+	err := parse_with("cmdline cmd -- x -- y", func(p *Parser) {
+		if p.IsArg() {
+			args = append(args, p.Arg())
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, strings.Join(args, " "), "cmd x -- y")
+}
+
+func TestOptionTerminator(t *testing.T) {
+	defer func() {
+		OptionTerminator, DisableOptionTerminator = "--", false
+	}()
+	OptionTerminator = "///"
+
+	verbose := false
+	args := []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+			verbose = true
+		case p.IsArg():
+			args = append(args, p.Arg())
+		}
+	}
+
+	err := parse_with("cmd /// -v", fn)
+	assertSuccess(t, err)
+	assertFalse(t, verbose)
+	assertEqual(t, strings.Join(args, " "), "-v")
+
+	args = nil
+	config := &Config{ErrorFunc: ReturnError}
+	err = config.ParseArgs(strings.Fields("cmd -- -v"), fn)
+	assertSuccess(t, err)
+	assertFalse(t, verbose)
+	assertEqual(t, strings.Join(args, " "), "-v")
+
+	args = nil
+	config = &Config{ErrorFunc: ReturnError, OptionTerminator: "@@"}
+	err = config.ParseArgs(strings.Fields("cmd @@ -v"), fn)
+	assertSuccess(t, err)
+	assertFalse(t, verbose)
+	assertEqual(t, strings.Join(args, " "), "-v")
+
+	config = &Config{ErrorFunc: ReturnError, DisableOptionTerminator: true}
+	err = config.ParseArgs(strings.Fields("cmd -- -v"), fn)
+	assertError(t, err, "Unbekannte Option: --???")
+
+	args = nil
+	OptionTerminator = ""
+	err = parse_with("cmd -- -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, strings.Join(args, " "), "-v")
+
+	DisableOptionTerminator = true
+	err = parse_with("cmd -- -v", fn)
+	assertError(t, err, "Unbekannte Option: --???")
+}
+
+func TestPosixlyCorrect(t *testing.T) {
+	defer func() {
+		PosixlyCorrect = false
+	}()
+
+	opts, err := parse_cmdline("cmdline cmd --verbose")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, len(opts.args), 0)
+
+	PosixlyCorrect = true
+
+	opts, err = parse_cmdline("cmdline -v cmd --verbose")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.cmd, "cmd")
+	assertEqual(t, len(opts.args), 1)
+	assertEqual(t, opts.args[0], "--verbose")
+
+	msg, args := "", []string{}
+	fn := func(p *Parser) {
+		switch {
+		case p.IsCmd("commit"):
+			p.ParseRest(func(p *Parser) {
+				switch {
+				case p.IsStrOpt("message", "m"):
+					msg = p.StrVal()
+				case p.IsArg():
+					args = append(args, p.Arg())
+				}
+			})
+		}
+	}
+
+	err = parse_with("cmdline commit -m hi file -m x", fn)
+	assertSuccess(t, err)
+	assertEqual(t, msg, "hi")
+	assertEqual(t, strings.Join(args, " "), "file -m x")
+
+	msg, args = "", nil
+	err = parse_with("cmdline -- commit -m hi", fn)
+	assertSuccess(t, err)
+	assertEqual(t, msg, "")
+	assertEqual(t, strings.Join(args, " "), "-m hi")
+}
+
+func TestPassThroughUnknownOptions(t *testing.T) {
+	defer func() {
+		PassThroughUnknownOptions = false
+	}()
+
+	_, err := parse_cmdline("cmdline cmd --unknown")
+	assertError(t, err, "Unbekannte Option: --unknown")
+
+	PassThroughUnknownOptions = true
+
+	opts, err := parse_cmdline("cmdline cmd --unknown=1 -v -x")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.cmd, "cmd")
+	assertEqual(t, strings.Join(opts.args, " "), "--unknown=1 -x")
+}
+
+func TestRejectDuplicateOptions(t *testing.T) {
+	defer func() {
+		RejectDuplicateOptions = false
+	}()
+
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrOpt("file", "f"):
+		case p.IsCountOpt("verbose", "v"):
+		case p.IsStrSliceOpt("include", "I"):
+		}
+	}
+
+	err := parse_with("cmdline --file a -f b", fn)
+	assertSuccess(t, err)
+
+	RejectDuplicateOptions = true
+
+	err = parse_with("cmdline --file a -f b", fn)
+	assertError(t, err, "Option --file mehrfach angegeben")
+
+	var parseErr *ParseError
+	assertTrue(t, errors.As(err, &parseErr))
+	assertEqual(t, parseErr.Kind, DuplicateOption)
+
+	err = parse_with("cmdline -vv --verbose -I a --include=b --file a", fn)
+	assertSuccess(t, err)
+
+	fn = func(p *Parser) {
+		switch {
+		case p.IsBoolOpt("verbose", "v"):
+		case p.IsPlusOpt("verbose", "v"):
+		}
+	}
+
+	err = parse_with("cmdline --verbose --no-verbose", fn)
+	assertError(t, err, "Option --verbose mehrfach angegeben")
+
+	err = parse_with("cmdline -v +v", fn)
+	assertError(t, err, "Option --verbose mehrfach angegeben")
+}
+
+func TestMaxOptValueLen(t *testing.T) {
+	defer func() {
+		MaxOptValueLen = 0
+	}()
+
+	long := strings.Repeat("x", 20)
+	_, err := parse_cmdline("cmdline --file=" + long)
+	assertSuccess(t, err)
+
+	MaxOptValueLen = 10
+
+	_, err = parse_cmdline("cmdline --file=" + long)
+	assertError(t, err, "Options-Argument zu lang (max 10 Zeichen): --file")
+
+	_, err = parse_cmdline("cmdline -f " + long)
+	assertError(t, err, "Options-Argument zu lang (max 10 Zeichen): --file")
+
+	opts, err := parse_cmdline("cmdline -f äöüäöüäöüä")
+	assertSuccess(t, err)
+	assertEqual(t, opts.file, "äöüäöüäöüä")
+}
+
+func TestOnToken(t *testing.T) {
+	defer func() {
+		OnToken = nil
+	}()
+
+	tokens := []string{}
+	OnToken = func(raw string, isOption bool) {
+		tokens = append(tokens, fmt.Sprintf("%s:%v", raw, isOption))
+	}
+
+	_, err := parse_cmdline("cmdline cmd -vl2 --file f.txt a -- -t")
+	assertSuccess(t, err)
+	assertEqual(t, strings.Join(tokens, " "), "cmd:false -v:true -l=2:true --file:true a:false -t:false")
+}
+
+func TestNegativeNumberArgs(t *testing.T) {
+	defer func() {
+		AllowNegativeNumberArgs = false
+	}()
+
+	_, err := parse_cmdline("cmdline -5 -3")
+	assertError(t, err, "Unbekannte Option: --5")
+
+	AllowNegativeNumberArgs = true
+
+	opts, err := parse_cmdline("cmdline -5 -3.5 -v")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.cmd, "-5")
+	assertEqual(t, len(opts.args), 1)
+	assertEqual(t, opts.args[0], "-3.5")
+}
+
+func TestResponseFiles(t *testing.T) {
+	defer func() {
+		ExpandResponseFiles = false
+	}()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "args.txt")
+	loop := filepath.Join(dir, "loop.txt")
+	os.WriteFile(file, []byte("--verbose\ncmd"), 0644)
+	os.WriteFile(loop, []byte("arg0 @"+loop), 0644)
+
+	opts, err := parse_cmdline("cmdline @" + file)
+	assertSuccess(t, err)
+	assertEqual(t, opts.cmd, "@"+file)
+
+	ExpandResponseFiles = true
+
+	opts, err = parse_cmdline("cmdline @" + file + " arg0")
+	assertSuccess(t, err)
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.cmd, "cmd")
+	assertEqual(t, len(opts.args), 1)
+	assertEqual(t, opts.args[0], "arg0")
+
+	_, err = parse_cmdline("cmdline @" + loop)
+	assertError(t, err, "Zu viele verschachtelte Argument-Dateien: @"+loop)
+
+	_, err = parse_cmdline("cmdline @" + dir + "/missing.txt")
+	assertError(t, err, "Argument-Datei kann nicht gelesen werden: "+dir+"/missing.txt")
+}
+
+func TestTooManyArgs(t *testing.T) {
+	_, err := parse_cmdline("cmdline cmd arg0 arg1 arg2")
+	assertError(t, err, "Zu viele Argumente!")
+}
+
+func TestUnknownOpt(t *testing.T) {
+	_, err := parse_cmdline("cmdline --verbose --unknown")
+	assertError(t, err, "Unbekannte Option: --unknown")
+}
+
+func TestParseError(t *testing.T) {
+	var parseErr *ParseError
+
+	_, err := parse_cmdline("cmdline --verbose --unknown")
+	assertTrue(t, errors.As(err, &parseErr))
+	assertEqual(t, parseErr.Kind, UnknownOption)
+	assertEqual(t, parseErr.Option, "unknown")
+
+	_, err = parse_cmdline("cmdline -l 4")
+	assertTrue(t, errors.As(err, &parseErr))
+	assertEqual(t, parseErr.Kind, InvalidValue)
+	assertEqual(t, parseErr.Option, "level")
+
+	_, err = parse_cmdline("cmdline cmd arg0 arg1 arg2")
+	assertTrue(t, errors.As(err, &parseErr))
+	assertEqual(t, parseErr.Kind, TooManyArgs)
+	assertEqual(t, parseErr.Option, "")
+}
+
+func TestEmptyOptVal(t *testing.T) {
+	opts, err := parse_cmdline("cmdline --file= cmd")
 	assertSuccess(t, err)
-	assertTrue(t, opts.verbose)
 	assertEqual(t, opts.file, "")
 	assertEqual(t, opts.cmd, "cmd")
-	assertEqual(t, opts.level, 0)
-	assertEqual(t, len(opts.args), 1)
-	assertEqual(t, opts.args[0], "--file=file.txt")
-}
 
-func TestTooManyArgs(t *testing.T) {
-	_, err := parse_cmdline("cmdline cmd arg0 arg1 arg2")
-	assertError(t, err, "Zu viele Argumente!")
+	_, err = parse_cmdline("cmdline --verbose=")
+	assertError(t, err, "Option erlaubt kein Options-Argument: --verbose")
+
+	_, err = parse_cmdline("cmdline --level=")
+	assertError(t, err, "Ungültige Zahl:  (Option --level)")
 }
 
-func TestUnknownOpt(t *testing.T) {
-	_, err := parse_cmdline("cmdline --verbose --unknown")
+func TestCollectAllErrors(t *testing.T) {
+	defer func() {
+		CollectAllErrors = false
+	}()
+
+	_, err := parse_cmdline("cmdline --unknown --level=9 -v")
 	assertError(t, err, "Unbekannte Option: --unknown")
+
+	CollectAllErrors = true
+
+	opts, err := parse_cmdline("cmdline --unknown --level=9 -v cmd a b c")
+	assertError(t, err, "Unbekannte Option: --unknown\n"+
+		"Zahl muß <= 3 sein: 9 (Option --level)\n"+
+		"Zu viele Argumente!")
+	assertTrue(t, opts.verbose)
+	assertEqual(t, opts.cmd, "cmd")
+
+	var parseErr *ParseError
+	assertTrue(t, errors.As(err, &parseErr))
+	assertEqual(t, parseErr.Kind, UnknownOption)
+
+	_, err = parse_cmdline("cmdline --level=9")
+	assertError(t, err, "Zahl muß <= 3 sein: 9 (Option --level)")
+
+	// eine statt des Options-Arguments angegebene Option wird danach ausgewertet
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrOpt("file", "f"):
+		case p.IsPairOpt("size", "s"):
+		}
+	}
+	err = parse_with("cmdline --file --bad --worse", fn)
+	assertError(t, err, "Option --file erwartet ein Argument, erhielt aber Option --bad\n"+
+		"Unbekannte Option: --bad\n"+
+		"Unbekannte Option: --worse")
+
+	err = parse_with("cmdline --size 1 --bad", fn)
+	assertError(t, err, "Option erwartet ein Options-Argument: --size\n"+
+		"Unbekannte Option: --bad")
+}
+
+func TestMessages(t *testing.T) {
+	defer func() {
+		Msgs = DefaultMessages
+	}()
+
+	Msgs.UnknownOption = "Unknown option: --%s"
+	Msgs.NumberTooLarge = "Number must be <= %v: %v (option --%s)"
+
+	_, err := parse_cmdline("cmdline --unknown")
+	assertError(t, err, "Unknown option: --unknown")
+
+	_, err = parse_cmdline("cmdline --threshold=2.5")
+	assertError(t, err, "Number must be <= 1: 2.5 (option --threshold)")
+
+	_, err = parse_cmdline("cmdline --file")
+	assertError(t, err, "Option erwartet ein Options-Argument: --file")
 }
 
 func TestMissingOptVal(t *testing.T) {
-	_, err := parse_cmdline("cmdline --file --verbose")
+	_, err := parse_cmdline("cmdline --file")
 	assertError(t, err, "Option erwartet ein Options-Argument: --file")
+
+	_, err = parse_cmdline("cmdline --file --verbose")
+	assertError(t, err, "Option --file erwartet ein Argument, erhielt aber Option --verbose")
+
+	_, err = parse_cmdline("cmdline -f -v")
+	assertError(t, err, "Option --file erwartet ein Argument, erhielt aber Option -v")
+
+	var parseErr *ParseError
+	assertTrue(t, errors.As(err, &parseErr))
+	assertEqual(t, parseErr.Kind, MissingValue)
 }
 
 func TestUnwantedOptVal(t *testing.T) {
@@ -127,6 +2100,330 @@ func TestInvalidIntVal(t *testing.T) {
 	assertError(t, err, "Zahl muß <= 3 sein: 4 (Option --level)")
 }
 
+func TestIntOptBase(t *testing.T) {
+	mode := 0
+	fn := func(p *Parser) {
+		switch {
+		case p.IsIntOpt("mode", "m", 0, 0777):
+			mode = p.IntVal()
+		}
+	}
+
+	err := parse_with("cmdline --mode=0x1F", fn)
+	assertSuccess(t, err)
+	assertEqual(t, mode, 31)
+
+	err = parse_with("cmdline --mode=0755", fn)
+	assertSuccess(t, err)
+	assertEqual(t, mode, 0755)
+
+	err = parse_with("cmdline -m 0o17", fn)
+	assertSuccess(t, err)
+	assertEqual(t, mode, 15)
+
+	err = parse_with("cmdline -m 0b101", fn)
+	assertSuccess(t, err)
+	assertEqual(t, mode, 5)
+
+	err = parse_with("cmdline -m 42", fn)
+	assertSuccess(t, err)
+	assertEqual(t, mode, 42)
+
+	err = parse_with("cmdline -m 0x1000", fn)
+	assertError(t, err, "Zahl muß <= 511 sein: 4096 (Option --mode)")
+}
+
+func TestValOk(t *testing.T) {
+	var strOk, intOk bool
+	fn := func(p *Parser) {
+		switch {
+		case p.IsStrOpt("file", "f"):
+		case p.IsIntOpt("level", "l", 0, 3):
+		case p.IsOpt("verbose", "v"):
+		}
+		_, strOk = p.StrValOk()
+		_, intOk = p.IntValOk()
+	}
+
+	err := parse_with("cmdline --level=2", fn)
+	assertSuccess(t, err)
+	assertTrue(t, strOk)
+	assertTrue(t, intOk)
+
+	err = parse_with("cmdline --level=2 --file=a", fn)
+	assertSuccess(t, err)
+	assertTrue(t, strOk)
+	assertFalse(t, intOk)
+
+	err = parse_with("cmdline --level=2 -v", fn)
+	assertSuccess(t, err)
+	assertFalse(t, strOk)
+	assertFalse(t, intOk)
+
+	err = parse_with("cmdline --level=9", fn)
+	assertError(t, err, "Zahl muß <= 3 sein: 9 (Option --level)")
+	assertFalse(t, intOk)
+
+	err = parse_with("cmdline --file", fn)
+	assertError(t, err, "Option erwartet ein Options-Argument: --file")
+	assertFalse(t, strOk)
+}
+
+func TestNegativeOptVal(t *testing.T) {
+	level, threshold := 0, 0.0
+	fn := func(p *Parser) {
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsIntOpt("level", "l", -3, 3):
+			level = p.IntVal()
+		case p.IsFloatOpt("threshold", "t", -1, 1):
+			threshold = p.FloatVal()
+		case p.IsStrOpt("file", "f"):
+		}
+	}
+
+	err := parse_with("cmdline -l -2 -v", fn)
+	assertSuccess(t, err)
+	assertEqual(t, level, -2)
+
+	err = parse_with("cmdline --level=-3", fn)
+	assertSuccess(t, err)
+	assertEqual(t, level, -3)
+
+	err = parse_with("cmdline --level -5", fn)
+	assertError(t, err, "Zahl muß >= -3 sein: -5 (Option --level)")
+
+	err = parse_with("cmdline -t -0.5", fn)
+	assertSuccess(t, err)
+	assertEqual(t, threshold, -0.5)
+
+	err = parse_with("cmdline -f -2", fn)
+	assertError(t, err, "Option --file erwartet ein Argument, erhielt aber Option -2")
+}
+
+func TestIntOptBounds(t *testing.T) {
+	n := 0
+	fn := func(p *Parser) {
+		switch {
+		case p.IsIntOptMin("min", "", 1):
+			n = p.IntVal()
+		case p.IsIntOptMax("max", "", 10):
+			n = p.IntVal()
+		case p.IsAnyIntOpt("any", "a"):
+			n = p.IntVal()
+		}
+	}
+
+	err := parse_with("cmdline --any=-2000000000", fn)
+	assertSuccess(t, err)
+	assertEqual(t, n, -2000000000)
+
+	err = parse_with("cmdline -a 2000000000", fn)
+	assertSuccess(t, err)
+	assertEqual(t, n, 2000000000)
+
+	err = parse_with("cmdline --min=1000000", fn)
+	assertSuccess(t, err)
+	assertEqual(t, n, 1000000)
+
+	err = parse_with("cmdline --min=0", fn)
+	assertError(t, err, "Zahl muß >= 1 sein: 0 (Option --min)")
+
+	err = parse_with("cmdline --max=-1000000", fn)
+	assertSuccess(t, err)
+	assertEqual(t, n, -1000000)
+
+	err = parse_with("cmdline --max=11", fn)
+	assertError(t, err, "Zahl muß <= 10 sein: 11 (Option --max)")
+}
+
+func TestIntSliceOpt(t *testing.T) {
+	var ids []int
+	fn := func(p *Parser) {
+		switch {
+		case p.IsIntSliceOpt("id", "i", 0, 100):
+			ids = p.IntSliceVal()
+		}
+	}
+
+	err := parse_with("cmdline --id 1 -i 2 --id=3,4, 5", fn)
+	assertError(t, err, "Zu viele Argumente!")
+
+	err = parse_with("cmdline --id 1 -i 2 --id=3,4,5", fn)
+	assertSuccess(t, err)
+	assertEqual(t, len(ids), 5)
+	for i, id := range ids {
+		assertEqual(t, id, i+1)
+	}
+
+	err = parse_with("cmdline --id=1,x,3", fn)
+	assertError(t, err, "Ungültige Zahl: x (Option --id)")
+
+	err = parse_with("cmdline --id=1,101", fn)
+	assertError(t, err, "Zahl muß <= 100 sein: 101 (Option --id)")
+}
+
+func TestUintOpt(t *testing.T) {
+	var count uint64
+	fn := func(p *Parser) {
+		switch {
+		case p.IsUintOpt("count", "c", math.MaxUint64):
+			count = p.UintVal()
+		case p.IsUintOpt("small", "s", 10):
+		}
+	}
+
+	err := parse_with("cmdline --count=18446744073709551615", fn)
+	assertSuccess(t, err)
+	assertEqual(t, count, uint64(math.MaxUint64))
+
+	err = parse_with("cmdline --count=18446744073709551616", fn)
+	assertError(t, err, "Ungültige Zahl: 18446744073709551616 (Option --count)")
+
+	err = parse_with("cmdline --count=-1", fn)
+	assertError(t, err, "Zahl darf nicht negativ sein: -1 (Option --count)")
+
+	err = parse_with("cmdline -s 11", fn)
+	assertError(t, err, "Zahl muß <= 10 sein: 11 (Option --small)")
+}
+
+func TestFloatOpt(t *testing.T) {
+	opts, err := parse_cmdline("cmdline -t 0.5")
+	assertSuccess(t, err)
+	assertEqual(t, opts.threshold, 0.5)
+
+	opts, err = parse_cmdline("cmdline --threshold=1e-1")
+	assertSuccess(t, err)
+	assertEqual(t, opts.threshold, 0.1)
+
+	_, err = parse_cmdline("cmdline --threshold=2.5")
+	assertError(t, err, "Zahl muß <= 1 sein: 2.5 (Option --threshold)")
+
+	_, err = parse_cmdline("cmdline --threshold=-0.5")
+	assertError(t, err, "Zahl muß >= 0 sein: -0.5 (Option --threshold)")
+
+	_, err = parse_cmdline("cmdline --threshold=abc")
+	assertError(t, err, "Ungültige Zahl: abc (Option --threshold)")
+}
+
+func TestSizeOpt(t *testing.T) {
+	var size int64
+	fn := func(p *Parser) {
+		switch {
+		case p.IsSizeOpt("max-size", "s"):
+			size = p.SizeVal()
+		}
+	}
+
+	tests := map[string]int64{
+		"1KiB": 1024,
+		"1MB":  1000000,
+		"10":   10,
+		"2k":   2000,
+		"1.5G": 1500000000,
+		"1TiB": 1 << 40,
+	}
+	for val, exp := range tests {
+		err := parse_with("cmdline --max-size="+val, fn)
+		assertSuccess(t, err)
+		assertEqual(t, size, exp)
+	}
+
+	err := parse_with("cmdline --max-size=10XB", fn)
+	assertError(t, err, "Ungültige Größe: 10XB (Option --max-size)")
+
+	err = parse_with("cmdline -s MB", fn)
+	assertError(t, err, "Ungültige Größe: MB (Option --max-size)")
+}
+
+func TestDurationOpt(t *testing.T) {
+	var timeout time.Duration
+	fn := func(p *Parser) {
+		switch {
+		case p.IsDurationOpt("timeout", "t"):
+			timeout = p.DurationVal()
+		}
+	}
+
+	err := parse_with("cmdline -t 1h30m", fn)
+	assertSuccess(t, err)
+	assertEqual(t, timeout, 90*time.Minute)
+
+	err = parse_with("cmdline --timeout=30s", fn)
+	assertSuccess(t, err)
+	assertEqual(t, timeout, 30*time.Second)
+
+	err = parse_with("cmdline --timeout=abc", fn)
+	assertError(t, err, "Ungültige Dauer: abc (Option --timeout)")
+}
+
+func TestEnumOpt(t *testing.T) {
+	color := ""
+	fn := func(p *Parser) {
+		switch {
+		case p.IsEnumOpt("color", "c", "always", "never", "auto"):
+			color = p.StrVal()
+		}
+	}
+
+	err := parse_with("cmdline --color=never", fn)
+	assertSuccess(t, err)
+	assertEqual(t, color, "never")
+
+	err = parse_with("cmdline -c Never", fn)
+	assertError(t, err, "Ungültiger Wert: Never (Option --color erlaubt: always, never, auto)")
+
+	defer func() {
+		assertTrue(t, recover() != nil)
+	}()
+	parse_with("cmdline --color=never", func(p *Parser) {
+		p.IsEnumOpt("color", "c")
+	})
+}
+
+func TestBuildHelp(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	parser := NewParser(strings.Fields("cmdline -v"))
+	err := parser.Parse(func(p *Parser) {
+		p.Flag("verbose", "v", "Verbose Meldungen")
+		p.Flag("file", "f", "Datei anzeigen. Die Datei wird vor dem Anzeigen auf "+
+			"Änderungen geprüft und gegebenenfalls neu eingelesen")
+
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("file", "f"):
+		}
+	})
+	assertSuccess(t, err)
+
+	exp := "| -v, --verbose    Verbose Meldungen\n" +
+		"| -f, --file=FILE  Datei anzeigen. Die Datei wird vor dem Anzeigen auf\n" +
+		"|                  Änderungen geprüft und gegebenenfalls neu eingelesen"
+
+	assertEqual(t, parser.BuildHelp(), exp)
+
+	help := ""
+	config := &Config{HelpFunc: func(h string) { help = h }}
+	err = config.ParseArgs(strings.Fields("cmdline --help"), func(p *Parser) {
+		p.Flag("verbose", "v", "Verbose Meldungen")
+		switch {
+		case p.IsOpt("verbose", "v"):
+		}
+	})
+	assertSuccess(t, err)
+	assertEqual(t, help, "Optionen:\n| -v, --verbose  Verbose Meldungen")
+
+	msgs := DefaultMessages
+	msgs.HelpHeading = "Options:"
+	config = &Config{HelpFunc: func(h string) { help = h }, Messages: &msgs}
+	err = config.ParseArgs(strings.Fields("cmdline --help"), func(p *Parser) {
+		p.IsOpt("verbose", "v")
+	})
+	assertSuccess(t, err)
+	assertEqual(t, help, "Options:\n| -v, --verbose")
+}
+
 func TestFormatHelp(t *testing.T) {
 	help := `Verwendung: cmd [OPTS]
 	
@@ -149,6 +2446,227 @@ func TestFormatHelp(t *testing.T) {
 	assertEqual(t, help, exp)
 }
 
+func TestFormatHelpLevels(t *testing.T) {
+	help := FormatHelp(`Kommandos:
+		| commit
+		|| -m, --message
+		|||tief
+
+		`)
+
+	exp := "Kommandos:\n" +
+		"  commit\n" +
+		"    -m, --message\n" +
+		"     tief"
+
+	assertEqual(t, help, exp)
+}
+
+func TestFormatHelpPrefix(t *testing.T) {
+	defer func() {
+		FormatHelpPrefix = "|"
+	}()
+	FormatHelpPrefix = ">"
+
+	help := FormatHelp(`Verwendung: cmd [OPTS]
+		> -v, --verbose
+		| a|b`)
+
+	exp := "Verwendung: cmd [OPTS]\n" +
+		"  -v, --verbose\n" +
+		"| a|b"
+
+	assertEqual(t, help, exp)
+
+	FormatHelpPrefix = ""
+	assertEqual(t, FormatHelp("Usage\n  | foo\n"), "Usage\n| foo")
+}
+
+func TestFormatHelpPreserveIndent(t *testing.T) {
+	defer func() {
+		FormatHelpPreserveIndent = false
+	}()
+	FormatHelpPreserveIndent = true
+
+	help := FormatHelp(`Verwendung: cmd [OPTS]
+
+		Beispiel:
+		    cmd -v file.txt
+		      | weiter
+
+		Optionen:
+		| -v, --verbose
+		`)
+
+	exp := "Verwendung: cmd [OPTS]\n" +
+		"\n" +
+		"Beispiel:\n" +
+		"    cmd -v file.txt\n" +
+		"  weiter\n" +
+		"\n" +
+		"Optionen:\n" +
+		"  -v, --verbose"
+
+	assertEqual(t, help, exp)
+}
+
+func TestFormatHelpWidth(t *testing.T) {
+	help := FormatHelpWidth(`Verwendung: cmd [OPTS]
+
+		Optionen:
+		| -v, --verbose  Gibt zusätzliche Meldungen über den Fortschritt aus
+		| -f, --file     Datei`, 40)
+
+	exp := "Verwendung: cmd [OPTS]\n" +
+		"\n" +
+		"Optionen:\n" +
+		"  -v, --verbose  Gibt zusätzliche\n" +
+		"                 Meldungen über den\n" +
+		"                 Fortschritt aus\n" +
+		"  -f, --file     Datei"
+
+	assertEqual(t, help, exp)
+
+	t.Setenv("COLUMNS", "30")
+	help = FormatHelpWidth("Ein sehr langer Text, der umgebrochen werden muß", 0)
+	assertEqual(t, help, "Ein sehr langer Text, der\numgebrochen werden muß")
+}
+
+func TestAutoFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	format := AutoFormat(&buf, strings.ToUpper)
+	assertEqual(t, format("abc"), "abc")
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	assertSuccess(t, err)
+	defer file.Close()
+
+	format = AutoFormat(file, strings.ToUpper)
+	assertEqual(t, format("abc"), "abc")
+}
+
+func TestFprintHelp(t *testing.T) {
+	var buf bytes.Buffer
+
+	FprintHelp(&buf, `Verwendung: cmd [OPTS]
+
+	Optionen:
+	| -v, --verbose
+	`)
+
+	exp := "Verwendung: cmd [OPTS]\n" +
+		"\n" +
+		"Optionen:\n" +
+		"  -v, --verbose\n"
+
+	assertEqual(t, buf.String(), exp)
+}
+
+func TestFormatHelpFunc(t *testing.T) {
+	var buf bytes.Buffer
+
+	defer func() {
+		FormatHelpFunc = DontFormat
+	}()
+	FormatHelpFunc = strings.ToUpper
+
+	FprintHelp(&buf, `Verwendung: cmd [OPTS]
+		| -v, --verbose`)
+	assertEqual(t, buf.String(), "VERWENDUNG: CMD [OPTS]\n  -V, --VERBOSE\n")
+}
+
+func TestProgramMessage(t *testing.T) {
+	var buf bytes.Buffer
+
+	program := Program
+	Program = "cmdline"
+	defer func() {
+		Program = program
+	}()
+
+	ProgramMessage(&buf, "Fehler: %s", "file.txt")
+	assertEqual(t, buf.String(), "cmdline: Fehler: file.txt\n")
+
+	buf.Reset()
+	ProgramMessage(&buf, "Zeile 1\nZeile 2\n\nZeile 4")
+	exp := "cmdline: Zeile 1\n" +
+		"         Zeile 2\n" +
+		"\n" +
+		"         Zeile 4\n"
+	assertEqual(t, buf.String(), exp)
+}
+
+// Die Ausgaben von SyntaxError bzw. PrintVersion beenden das Programm und werden
+// daher in einem eigenen Prozess getestet.
+func TestConfigProgramOutput(t *testing.T) {
+	if mode := os.Getenv("CMDLINE_TEST_OUTPUT"); mode != "" {
+		Program = ""
+		config := &Config{Version: "1.0"}
+		config.ParseArgs([]string{"/usr/bin/mytool", mode}, func(p *Parser) {})
+		return
+	}
+
+	run := func(arg string) string {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestConfigProgramOutput$")
+		cmd.Env = append(os.Environ(), "CMDLINE_TEST_OUTPUT="+arg)
+		out, _ := cmd.CombinedOutput()
+		return string(out)
+	}
+
+	out := run("--bad")
+	assertTrue(t, strings.HasPrefix(out, "mytool: Unbekannte Option: --bad\n"))
+	out = run("--version")
+	assertTrue(t, strings.HasPrefix(out, "mytool 1.0\n"))
+}
+
+func TestStdoutStderr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	program := Program
+	Program = "cmdline"
+	Stdout, Stderr = &stdout, &stderr
+	defer func() {
+		Program = program
+		Stdout, Stderr = os.Stdout, os.Stderr
+	}()
+
+	Info("Datei %s kopiert", "file.txt")
+	assertEqual(t, stdout.String(), "cmdline: Datei file.txt kopiert\n")
+	assertEqual(t, stderr.String(), "")
+
+	Warn("Datei %s fehlt", "file.txt")
+	assertEqual(t, stderr.String(), "cmdline: Datei file.txt fehlt\n")
+}
+
+func TestNewRuntimeError(t *testing.T) {
+	program := Program
+	Program = "cmdline"
+	defer func() {
+		Program = program
+	}()
+
+	err := NewRuntimeError("Datei %s fehlt", "file.txt")
+	assertError(t, err, "cmdline: Datei file.txt fehlt")
+}
+
+func TestMessageSeparator(t *testing.T) {
+	var buf bytes.Buffer
+
+	program := Program
+	Program = "cmdline"
+	MessageSeparator = " - "
+	defer func() {
+		Program = program
+		MessageSeparator = ": "
+	}()
+
+	ProgramMessage(&buf, "Zeile 1\nZeile 2")
+	exp := "cmdline - Zeile 1\n" +
+		"          Zeile 2\n"
+	assertEqual(t, buf.String(), exp)
+}
+
 //--------------------------------------------------------------------------------
 // Assertions
 //--------------------------------------------------------------------------------
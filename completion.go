@@ -0,0 +1,210 @@
+package cmdline
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+//--------------------------------------------------------------------------------
+// Shell-Completion
+//--------------------------------------------------------------------------------
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Liefert alle Options-Namen mit "-" bzw. "--" für die Completion.
+// Optionen mit Options-Argument werden zusätzlich in valueOpts geliefert.
+func (parser *Parser) completionOpts() (opts, valueOpts []string) {
+	for _, info := range parser.knownOpts() {
+		names := []string{}
+		for _, long := range info.longNames() {
+			names = append(names, "--"+long)
+		}
+		if info.short != "" {
+			names = append(names, "-"+info.short)
+		}
+		opts = append(opts, names...)
+		if info.hasValue {
+			valueOpts = append(valueOpts, names...)
+		}
+	}
+	if !parser.config.DisableAutoHelp {
+		opts = append(opts, "--help")
+	}
+	if parser.config.Version != "" {
+		opts = append(opts, "--version")
+	}
+	return opts, valueOpts
+}
+
+// Schreibt ein Bash-Completion-Skript für die bekannten Optionen und
+// Unterkommandos (siehe [Parser.IsCmd]) nach w.
+// Kann z.B. für ein Unterkommando "completion" verwendet werden:
+//
+//	case p.IsCmd("completion"):
+//	    p.GenerateBashCompletion(os.Stdout)
+//
+// Das Skript wird mit "source <(mytool completion)" aktiviert.
+func (parser *Parser) GenerateBashCompletion(w io.Writer) {
+	opts, valueOpts := parser.completionOpts()
+	funcName := "_" + nonIdentChars.ReplaceAllString(parser.config.Program, "_") + "_completion"
+
+	fmt.Fprintf(w, "%s() {\n", funcName)
+	fmt.Fprintf(w, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(w, "    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(w, "    local opts=\"%s\"\n", strings.Join(opts, " "))
+	fmt.Fprintf(w, "    local cmds=\"%s\"\n", strings.Join(parser.commands, " "))
+	fmt.Fprintf(w, "\n")
+	if len(valueOpts) > 0 {
+		fmt.Fprintf(w, "    case \"$prev\" in\n")
+		fmt.Fprintf(w, "    %s)\n", strings.Join(valueOpts, "|"))
+		fmt.Fprintf(w, "        COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		fmt.Fprintf(w, "        return\n")
+		fmt.Fprintf(w, "        ;;\n")
+		fmt.Fprintf(w, "    esac\n")
+		fmt.Fprintf(w, "\n")
+	}
+	fmt.Fprintf(w, "    if [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "    elif [[ -n \"$cmds\" && $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W \"$cmds\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "    else\n")
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", funcName, parser.config.Program)
+}
+
+// Schreibt ein Zsh-Completion-Skript für die bekannten Optionen und
+// Unterkommandos nach w. Die Beschreibungen aus [Parser.Flag] werden im
+// Completion-Menü angezeigt.
+func (parser *Parser) GenerateZshCompletion(w io.Writer) {
+	fmt.Fprintf(w, "#compdef %s\n", parser.config.Program)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "_arguments -s \\\n")
+
+	for _, info := range parser.knownOpts() {
+		desc := zshEscape(info.desc)
+		action, longSuffix, shortSuffix := "", "", ""
+		if info.hasValue {
+			longSuffix, shortSuffix = "=", "+"
+			action = ":" + info.long + ":_files"
+		}
+		long, short := "--"+info.long+longSuffix, "-"+info.short+shortSuffix
+		if info.short == "" {
+			fmt.Fprintf(w, "    '%s[%s]%s' \\\n", long, desc, action)
+		} else {
+			fmt.Fprintf(w, "    '(-%s --%s)'{%s,%s}'[%s]%s' \\\n", info.short, info.long, short, long, desc, action)
+		}
+		for _, alias := range info.aliases {
+			fmt.Fprintf(w, "    '--%s%s[%s]%s' \\\n", alias, longSuffix, desc, action)
+		}
+	}
+
+	if !parser.config.DisableAutoHelp {
+		fmt.Fprintf(w, "    '--help[%s]' \\\n", zshEscape(parser.msgs().HelpDescription))
+	}
+	if parser.config.Version != "" {
+		fmt.Fprintf(w, "    '--version[%s]' \\\n", zshEscape(parser.msgs().VersionDescription))
+	}
+	if len(parser.commands) > 0 {
+		fmt.Fprintf(w, "    '1:command:(%s)' \\\n", strings.Join(parser.commands, " "))
+	}
+	fmt.Fprintf(w, "    '*:file:_files'\n")
+}
+
+// Schreibt ein Fish-Completion-Skript für die bekannten Optionen und
+// Unterkommandos nach w. Die Beschreibungen aus [Parser.Flag] werden im
+// Completion-Menü angezeigt.
+func (parser *Parser) GenerateFishCompletion(w io.Writer) {
+	program := parser.config.Program
+
+	for _, info := range parser.knownOpts() {
+		fmt.Fprintf(w, "complete -c %s", program)
+		if info.short != "" {
+			fmt.Fprintf(w, " -s %s", info.short)
+		}
+		for _, long := range info.longNames() {
+			fmt.Fprintf(w, " -l %s", long)
+		}
+		if info.hasValue {
+			fmt.Fprintf(w, " -r")
+		}
+		if info.desc != "" {
+			fmt.Fprintf(w, " -d %s", fishQuote(info.desc))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	if !parser.config.DisableAutoHelp {
+		fmt.Fprintf(w, "complete -c %s -l help -d %s\n", program, fishQuote(parser.msgs().HelpDescription))
+	}
+	if parser.config.Version != "" {
+		fmt.Fprintf(w, "complete -c %s -l version -d %s\n", program, fishQuote(parser.msgs().VersionDescription))
+	}
+	if len(parser.commands) > 0 {
+		fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -f -a %s\n",
+			program, fishQuote(strings.Join(parser.commands, " ")))
+	}
+}
+
+var zshReplacer = strings.NewReplacer(`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`)
+
+func zshEscape(s string) string {
+	return zshReplacer.Replace(s)
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+//--------------------------------------------------------------------------------
+// Man-Page
+//--------------------------------------------------------------------------------
+
+// Schreibt die bekannten Optionen als roff-Einträge (".TP") für den
+// Abschnitt OPTIONS einer Man-Page nach w. Lange und kurze Option stehen
+// jeweils zusammen, darunter die Beschreibung aus [Parser.Flag].
+func (parser *Parser) GenerateManOptions(w io.Writer) {
+	for _, info := range parser.knownOpts() {
+		fmt.Fprintf(w, ".TP\n")
+		if info.short != "" {
+			fmt.Fprintf(w, "\\fB\\-%s\\fR, ", roffEscape(info.short))
+		}
+		fmt.Fprintf(w, "\\fB\\-\\-%s\\fR", roffEscape(info.long))
+		for _, alias := range info.aliases {
+			fmt.Fprintf(w, ", \\fB\\-\\-%s\\fR", roffEscape(alias))
+		}
+		if info.hasValue {
+			fmt.Fprintf(w, "=\\fI%s\\fR", roffEscape(strings.ToUpper(info.long)))
+		}
+		fmt.Fprintf(w, "\n")
+		if info.desc != "" {
+			fmt.Fprintf(w, "%s\n", roffLine(info.desc))
+		}
+	}
+
+	if !parser.config.DisableAutoHelp {
+		fmt.Fprintf(w, ".TP\n\\fB\\-\\-help\\fR\n%s\n", roffLine(parser.msgs().HelpDescription))
+	}
+	if parser.config.Version != "" {
+		fmt.Fprintf(w, ".TP\n\\fB\\-\\-version\\fR\n%s\n", roffLine(parser.msgs().VersionDescription))
+	}
+}
+
+var roffReplacer = strings.NewReplacer(`\`, `\e`, `-`, `\-`)
+
+func roffEscape(s string) string {
+	return roffReplacer.Replace(s)
+}
+
+// Maskiert s als Text-Zeile (ein führender Punkt bzw. Apostroph wäre ein roff-Befehl).
+func roffLine(s string) string {
+	s = roffEscape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package cmdline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func parse_completion(t *testing.T, generate func(p *Parser)) {
+	config := &Config{Program: "my-tool", ErrorFunc: ReturnError}
+	err := config.ParseArgs(strings.Fields("my-tool completion"), func(p *Parser) {
+		p.Flag("verbose", "v", "Verbose Meldungen")
+		p.Flag("file", "f", "Datei anzeigen")
+
+		switch {
+		case p.IsOpt("verbose", "v"):
+		case p.IsStrOpt("file", "f"):
+		case p.IsCmd("commit"):
+		case p.IsCmd("completion"):
+			generate(p)
+		}
+	})
+	assertSuccess(t, err)
+}
+
+func TestBashCompletion(t *testing.T) {
+	var buf bytes.Buffer
+	parse_completion(t, func(p *Parser) {
+		p.GenerateBashCompletion(&buf)
+	})
+	script := buf.String()
+
+	assertTrue(t, strings.Contains(script, `local opts="--verbose -v --file -f --help"`))
+	assertTrue(t, strings.Contains(script, `local cmds="commit completion"`))
+	assertTrue(t, strings.Contains(script, "    --file|-f)\n"))
+	assertTrue(t, strings.Contains(script, "complete -F _my_tool_completion my-tool\n"))
+}
+
+func TestZshCompletion(t *testing.T) {
+	var buf bytes.Buffer
+	parse_completion(t, func(p *Parser) {
+		p.GenerateZshCompletion(&buf)
+	})
+	script := buf.String()
+
+	assertTrue(t, strings.HasPrefix(script, "#compdef my-tool\n"))
+	assertTrue(t, strings.Contains(script, "_arguments -s \\\n"))
+	assertTrue(t, strings.Contains(script, `'(-v --verbose)'{-v,--verbose}'[Verbose Meldungen]'`))
+	assertTrue(t, strings.Contains(script, `'(-f --file)'{-f+,--file=}'[Datei anzeigen]:file:_files'`))
+	assertTrue(t, strings.Contains(script, `'1:command:(commit completion)'`))
+}
+
+func TestFishCompletion(t *testing.T) {
+	var buf bytes.Buffer
+	parse_completion(t, func(p *Parser) {
+		p.GenerateFishCompletion(&buf)
+	})
+	script := buf.String()
+
+	assertTrue(t, strings.Contains(script, "complete -c my-tool -s v -l verbose -d 'Verbose Meldungen'\n"))
+	assertTrue(t, strings.Contains(script, "complete -c my-tool -s f -l file -r -d 'Datei anzeigen'\n"))
+	assertTrue(t, strings.Contains(script, "complete -c my-tool -n __fish_use_subcommand -f -a 'commit completion'\n"))
+}
+
+func TestManOptions(t *testing.T) {
+	var buf bytes.Buffer
+	parse_completion(t, func(p *Parser) {
+		p.GenerateManOptions(&buf)
+	})
+	man := buf.String()
+
+	assertTrue(t, strings.HasPrefix(man, ".TP\n"))
+	assertTrue(t, strings.Contains(man, ".TP\n\\fB\\-v\\fR, \\fB\\-\\-verbose\\fR\nVerbose Meldungen\n"))
+	assertTrue(t, strings.Contains(man, ".TP\n\\fB\\-f\\fR, \\fB\\-\\-file\\fR=\\fIFILE\\fR\nDatei anzeigen\n"))
+	assertTrue(t, strings.Contains(man, ".TP\n\\fB\\-\\-help\\fR\n"))
+}
+
+func TestCompletionMessages(t *testing.T) {
+	msgs := DefaultMessages
+	msgs.HelpDescription = "show this help"
+	msgs.VersionDescription = "show the version"
+
+	var zsh, fish, man bytes.Buffer
+	config := &Config{Program: "my-tool", Version: "1.0", Messages: &msgs, ErrorFunc: ReturnError}
+	err := config.ParseArgs(strings.Fields("my-tool completion"), func(p *Parser) {
+		switch {
+		case p.IsCmd("completion"):
+			p.GenerateZshCompletion(&zsh)
+			p.GenerateFishCompletion(&fish)
+			p.GenerateManOptions(&man)
+		}
+	})
+	assertSuccess(t, err)
+
+	assertTrue(t, strings.Contains(zsh.String(), "'--help[show this help]'"))
+	assertTrue(t, strings.Contains(zsh.String(), "'--version[show the version]'"))
+	assertTrue(t, strings.Contains(fish.String(), "complete -c my-tool -l help -d 'show this help'\n"))
+	assertTrue(t, strings.Contains(fish.String(), "complete -c my-tool -l version -d 'show the version'\n"))
+	assertTrue(t, strings.Contains(man.String(), ".TP\n\\fB\\-\\-help\\fR\nshow this help\n"))
+	assertTrue(t, strings.Contains(man.String(), ".TP\n\\fB\\-\\-version\\fR\nshow the version\n"))
+}
@@ -0,0 +1,110 @@
+package cmdline
+
+// Enthält die Vorlagen für alle Meldungen des Parsers im Format von [fmt.Sprintf].
+// Die Reihenfolge der Platzhalter ist jeweils im Kommentar angegeben.
+type Messages struct {
+	// Hinweis von SyntaxError(): (Meldung)
+	UseHelp string
+	// (Option)
+	UnknownOption string
+	// Vorschlag, an UnknownOption angehängt: (ähnliche Option)
+	DidYouMean string
+	// ohne Platzhalter
+	TooManyArgs string
+	// (Minimum)
+	TooFewArgsMin string
+	// (Name des Arguments)
+	MissingArg string
+	// (Maximum)
+	TooManyArgsMax string
+	// (Option)
+	UnexpectedValue string
+	// (Option)
+	MissingValue string
+	// statt des Options-Arguments folgt eine Option: (Option, folgende Option)
+	OptionInsteadOfValue string
+	// (Option)
+	MissingOption string
+	// (Option)
+	AmbiguousOption string
+	// (Option, Option)
+	ConflictingOptions string
+	// (Option, benötigte Option)
+	DependentOption string
+	// (Option)
+	DuplicateOption string
+	// (maximale Länge, Option)
+	ValueTooLong string
+	// (Wert, Option)
+	InvalidNumber string
+	// (Minimum, Zahl, Option)
+	NumberTooSmall string
+	// (Maximum, Zahl, Option)
+	NumberTooLarge string
+	// (Wert, Option)
+	NegativeNumber string
+	// (Wert, Option)
+	InvalidBool string
+	// (Wert, Option, erlaubte Werte)
+	InvalidChoice string
+	// (Fehler der Prüf-Funktion, Option)
+	ValidationFailed string
+	// (Wert, Option)
+	InvalidDuration string
+	// (Wert, Option)
+	InvalidSize string
+	// (Datei)
+	UnreadableResponseFile string
+	// (@Datei)
+	NestedResponseFiles string
+	// (Anführungszeichen)
+	UnclosedQuote string
+	// Fehler von LoadDefaults(): (Datei, Zeilennummer, Zeile)
+	InvalidDefaultsLine string
+	// Überschrift der automatisch erzeugten Hilfe (ohne Platzhalter)
+	HelpHeading string
+	// Beschreibung von --help in den Completion-Skripten und der Man-Page (ohne Platzhalter)
+	HelpDescription string
+	// Beschreibung von --version in den Completion-Skripten und der Man-Page (ohne Platzhalter)
+	VersionDescription string
+}
+
+// die deutschen Standard-Meldungen
+var DefaultMessages = Messages{
+	UseHelp:                "%s\n\nVerwenden Sie --help für weitere Hilfe!",
+	UnknownOption:          "Unbekannte Option: --%s",
+	DidYouMean:             "Meinten Sie --%s?",
+	TooManyArgs:            "Zu viele Argumente!",
+	TooFewArgsMin:          "Zu wenige Argumente (mindestens %d)",
+	MissingArg:             "Fehlendes Argument: %s",
+	TooManyArgsMax:         "Zu viele Argumente (höchstens %d)",
+	UnexpectedValue:        "Option erlaubt kein Options-Argument: --%s",
+	MissingValue:           "Option erwartet ein Options-Argument: --%s",
+	OptionInsteadOfValue:   "Option --%s erwartet ein Argument, erhielt aber Option %s",
+	MissingOption:          "Fehlende Option: --%s",
+	AmbiguousOption:        "Mehrdeutige Option: --%s",
+	ConflictingOptions:     "Optionen --%s und --%s schließen sich gegenseitig aus",
+	DependentOption:        "Option --%s benötigt --%s",
+	DuplicateOption:        "Option --%s mehrfach angegeben",
+	ValueTooLong:           "Options-Argument zu lang (max %d Zeichen): --%s",
+	InvalidNumber:          "Ungültige Zahl: %s (Option --%s)",
+	NumberTooSmall:         "Zahl muß >= %v sein: %v (Option --%s)",
+	NumberTooLarge:         "Zahl muß <= %v sein: %v (Option --%s)",
+	NegativeNumber:         "Zahl darf nicht negativ sein: %s (Option --%s)",
+	InvalidBool:            "Ungültiger Wahrheitswert: %s (Option --%s)",
+	InvalidChoice:          "Ungültiger Wert: %s (Option --%s erlaubt: %s)",
+	ValidationFailed:       "%v (Option --%s)",
+	InvalidDuration:        "Ungültige Dauer: %s (Option --%s)",
+	InvalidSize:            "Ungültige Größe: %s (Option --%s)",
+	UnreadableResponseFile: "Argument-Datei kann nicht gelesen werden: %s",
+	NestedResponseFiles:    "Zu viele verschachtelte Argument-Dateien: %s",
+	UnclosedQuote:          "Fehlendes Anführungszeichen: %s",
+	InvalidDefaultsLine:    "%s, Zeile %d: Ungültige Zeile: %s",
+	HelpHeading:            "Optionen:",
+	HelpDescription:        "diese Hilfe",
+	VersionDescription:     "Version anzeigen",
+}
+
+// die Meldungen, die vom Parser und von SyntaxError() verwendet werden
+// (z.B. durch eine englische Übersetzung ersetzbar)
+var Msgs = DefaultMessages
@@ -0,0 +1,93 @@
+package cmdline
+
+import (
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Parst die übergebenen Argumente (siehe [ParseArgs]) in ein struct.
+// v muß ein Zeiger auf ein struct sein. Die Options-Namen werden mit dem
+// Tag `cmdline:"long,short"` festgelegt, Felder ohne Tag werden ignoriert.
+// Unterstützt werden Felder vom Typ bool ([Parser.IsOpt]), string ([Parser.IsStrOpt]),
+// int ([Parser.IsIntOpt]), float64 ([Parser.IsFloatOpt]) und []string ([Parser.IsStrSliceOpt]).
+// Ein []string-Feld mit dem Tag `cmdline:"args"` erhält alle Argumente.
+//
+//	var opts struct {
+//	    Verbose bool     `cmdline:"verbose,v"`
+//	    File    string   `cmdline:"file,f"`
+//	    Level   int      `cmdline:"level,l"`
+//	    Args    []string `cmdline:"args"`
+//	}
+//
+//	err := cmdline.ParseInto(&opts, os.Args)
+func ParseInto(v any, args []string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		panic("ParseInto: v muß ein Zeiger auf ein struct sein")
+	}
+	st := rv.Elem()
+
+	return ParseArgs(args, func(p *Parser) {
+		for i := 0; i < st.NumField(); i++ {
+			field := st.Type().Field(i)
+			tag, ok := field.Tag.Lookup("cmdline")
+			if !ok || !field.IsExported() {
+				continue
+			}
+			if parseField(p, tag, st.Field(i)) {
+				return
+			}
+		}
+	})
+}
+
+// Prüft die aktuelle Option bzw. das aktuelle Argument für ein Feld von [ParseInto].
+func parseField(p *Parser, tag string, value reflect.Value) bool {
+	if tag == "args" {
+		if value.Type() != reflect.TypeOf([]string{}) {
+			panic("ParseInto: Feld für Argumente muß vom Typ []string sein")
+		}
+		if !p.IsArg() {
+			return false
+		}
+		value.Set(reflect.Append(value, reflect.ValueOf(p.Arg())))
+		return true
+	}
+
+	long, short, _ := strings.Cut(tag, ",")
+
+	switch value.Kind() {
+	case reflect.Bool:
+		if p.IsOpt(long, short) {
+			value.SetBool(true)
+			return true
+		}
+	case reflect.String:
+		if p.IsStrOpt(long, short) {
+			value.SetString(p.StrVal())
+			return true
+		}
+	case reflect.Int:
+		if p.IsIntOpt(long, short, math.MinInt, math.MaxInt) {
+			value.SetInt(int64(p.IntVal()))
+			return true
+		}
+	case reflect.Float64:
+		if p.IsFloatOpt(long, short, math.Inf(-1), math.Inf(1)) {
+			value.SetFloat(p.FloatVal())
+			return true
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			panic("ParseInto: nicht unterstützter Typ für Option --" + long)
+		}
+		if p.IsStrSliceOpt(long, short) {
+			value.Set(reflect.ValueOf(p.StrSliceVal()))
+			return true
+		}
+	default:
+		panic("ParseInto: nicht unterstützter Typ für Option --" + long)
+	}
+	return false
+}
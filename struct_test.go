@@ -0,0 +1,33 @@
+package cmdline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseInto(t *testing.T) {
+	ErrorFunc = ReturnError
+
+	var opts struct {
+		Verbose   bool     `cmdline:"verbose,v"`
+		File      string   `cmdline:"file,f"`
+		Level     int      `cmdline:"level,l"`
+		Threshold float64  `cmdline:"threshold"`
+		Include   []string `cmdline:"include,I"`
+		Args      []string `cmdline:"args"`
+		ignored   string
+	}
+
+	err := ParseInto(&opts, strings.Fields("cmdline -v cmd --file=file.txt --level=-2 -I a arg --threshold=0.5 -I b"))
+	assertSuccess(t, err)
+	assertTrue(t, opts.Verbose)
+	assertEqual(t, opts.File, "file.txt")
+	assertEqual(t, opts.Level, -2)
+	assertEqual(t, opts.Threshold, 0.5)
+	assertEqual(t, strings.Join(opts.Include, " "), "a b")
+	assertEqual(t, strings.Join(opts.Args, " "), "cmd arg")
+	assertEqual(t, opts.ignored, "")
+
+	err = ParseInto(&opts, strings.Fields("cmdline --unknown"))
+	assertError(t, err, "Unbekannte Option: --unknown")
+}